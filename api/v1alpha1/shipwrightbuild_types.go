@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -22,6 +23,256 @@ const (
 	TriggersDeploymentDisabled TriggersDeployment = "Disabled"
 )
 
+// BuildStrategiesState indicates whether the embedded ClusterBuildStrategies should be installed.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type BuildStrategiesState string
+
+const (
+	// BuildStrategiesStateEnabled indicates that the embedded ClusterBuildStrategies should be
+	// installed.
+	BuildStrategiesStateEnabled BuildStrategiesState = "Enabled"
+	// BuildStrategiesStateDisabled indicates that no embedded ClusterBuildStrategy should be
+	// installed.
+	BuildStrategiesStateDisabled BuildStrategiesState = "Disabled"
+)
+
+// TektonManagement indicates whether the operator creates a TektonConfig when Tekton Pipelines is
+// not installed.
+// +kubebuilder:validation:Enum=Create;Never
+type TektonManagement string
+
+const (
+	// TektonManagementCreate indicates that the operator creates a TektonConfig when none exists.
+	TektonManagementCreate TektonManagement = "Create"
+	// TektonManagementNever indicates that the operator never creates a TektonConfig, which must
+	// be created beforehand.
+	TektonManagementNever TektonManagement = "Never"
+)
+
+// TektonInstall indicates how the operator installs Tekton Pipelines when it is missing.
+// +kubebuilder:validation:Enum=TektonConfig;Pipelines
+type TektonInstall string
+
+const (
+	// TektonInstallTektonConfig indicates that Tekton Pipelines is installed by the Tekton
+	// Operator, from a TektonConfig created by the operator.
+	TektonInstallTektonConfig TektonInstall = "TektonConfig"
+	// TektonInstallPipelines indicates that the operator installs and upgrades Tekton Pipelines
+	// itself, from the release manifest it embeds.
+	TektonInstallPipelines TektonInstall = "Pipelines"
+)
+
+// CertificatesMode selects how the certificates of the Shipwright Build webhook are managed.
+// +kubebuilder:validation:Enum=CertManager;BuiltIn;ServiceCA;Unmanaged
+type CertificatesMode string
+
+const (
+	// CertificatesModeCertManager indicates that the certificates are issued by cert-manager,
+	// which must be installed.
+	CertificatesModeCertManager CertificatesMode = "CertManager"
+	// CertificatesModeBuiltIn indicates that the operator generates a CA and the serving
+	// certificate itself, and rotates them before they expire.
+	CertificatesModeBuiltIn CertificatesMode = "BuiltIn"
+	// CertificatesModeServiceCA indicates that the certificates are issued by the OpenShift
+	// service CA.
+	CertificatesModeServiceCA CertificatesMode = "ServiceCA"
+	// CertificatesModeUnmanaged indicates that the certificates are not managed by the operator.
+	CertificatesModeUnmanaged CertificatesMode = "Unmanaged"
+)
+
+// IssuerKind is the kind of a cert-manager issuer.
+// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+type IssuerKind string
+
+const (
+	// IssuerKindIssuer references a namespaced Issuer, in the target namespace.
+	IssuerKindIssuer IssuerKind = "Issuer"
+	// IssuerKindClusterIssuer references a ClusterIssuer.
+	IssuerKindClusterIssuer IssuerKind = "ClusterIssuer"
+)
+
+// PrivateKeyAlgorithm is the algorithm of the private key of a certificate.
+// +kubebuilder:validation:Enum=RSA;ECDSA;Ed25519
+type PrivateKeyAlgorithm string
+
+const (
+	// PrivateKeyAlgorithmRSA generates RSA private keys.
+	PrivateKeyAlgorithmRSA PrivateKeyAlgorithm = "RSA"
+	// PrivateKeyAlgorithmECDSA generates ECDSA private keys.
+	PrivateKeyAlgorithmECDSA PrivateKeyAlgorithm = "ECDSA"
+	// PrivateKeyAlgorithmEd25519 generates Ed25519 private keys.
+	PrivateKeyAlgorithmEd25519 PrivateKeyAlgorithm = "Ed25519"
+)
+
+// BuildStrategyPolicy defines how the operator handles changes made to an installed
+// ClusterBuildStrategy.
+// +kubebuilder:validation:Enum=Overwrite;KeepUserChanges;Unmanaged
+type BuildStrategyPolicy string
+
+const (
+	// BuildStrategyPolicyOverwrite reverts any change made to the build strategy.
+	BuildStrategyPolicyOverwrite BuildStrategyPolicy = "Overwrite"
+	// BuildStrategyPolicyKeepUserChanges keeps the build strategy as-is once it was modified,
+	// and updates it otherwise.
+	BuildStrategyPolicyKeepUserChanges BuildStrategyPolicy = "KeepUserChanges"
+	// BuildStrategyPolicyUnmanaged leaves the build strategy alone: it is neither created,
+	// updated nor deleted by the operator.
+	BuildStrategyPolicyUnmanaged BuildStrategyPolicy = "Unmanaged"
+)
+
+// BuildStrategySourceReference references a ConfigMap or Secret holding ClusterBuildStrategy
+// manifests.
+type BuildStrategySourceReference struct {
+	// Name is the name of the ConfigMap or Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap or Secret. Defaults to the target namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key selects the entry holding the manifests. When empty, all entries are read.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// BuildStrategySource is a source of ClusterBuildStrategies installed alongside the embedded
+// ones. Exactly one of ConfigMap or Secret must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.secret)",message="exactly one of configMap or secret must be set"
+type BuildStrategySource struct {
+	// ConfigMap references a ConfigMap holding ClusterBuildStrategy manifests.
+	// +optional
+	ConfigMap *BuildStrategySourceReference `json:"configMap,omitempty"`
+
+	// Secret references a Secret holding ClusterBuildStrategy manifests.
+	// +optional
+	Secret *BuildStrategySourceReference `json:"secret,omitempty"`
+}
+
+// BuildStrategySourceStatus reports the ClusterBuildStrategies loaded from a source.
+type BuildStrategySourceStatus struct {
+	// Kind is the kind of the source, either ConfigMap or Secret.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the source.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the source.
+	Name string `json:"name"`
+
+	// BuildStrategies lists the names of the ClusterBuildStrategies loaded from the source.
+	// +optional
+	BuildStrategies []string `json:"buildStrategies,omitempty"`
+
+	// Error describes why the source could not be loaded. Empty when the source was loaded.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// BuildStrategiesSpec selects the embedded ClusterBuildStrategies to install.
+type BuildStrategiesSpec struct {
+	// State controls whether the embedded build strategies are installed.
+	// Defaults to "Enabled".
+	// +kubebuilder:default=Enabled
+	// +optional
+	State BuildStrategiesState `json:"state,omitempty"`
+
+	// Include lists the names of the build strategies to install. When empty, all embedded
+	// build strategies are installed.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude lists the names of the build strategies not to install. Exclusions take
+	// precedence over inclusions.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Policy defines how changes made to the installed build strategies are handled.
+	// Defaults to "Overwrite".
+	// +kubebuilder:default=Overwrite
+	// +optional
+	Policy BuildStrategyPolicy `json:"policy,omitempty"`
+
+	// Policies overrides the policy of individual build strategies, keyed by name.
+	// +optional
+	Policies map[string]BuildStrategyPolicy `json:"policies,omitempty"`
+
+	// Sources lists ConfigMaps and Secrets holding additional ClusterBuildStrategies to install.
+	// Their strategies are selected and managed like the embedded ones.
+	// +optional
+	Sources []BuildStrategySource `json:"sources,omitempty"`
+}
+
+// PolicyFor returns the policy that applies to the named build strategy.
+func (s *BuildStrategiesSpec) PolicyFor(name string) BuildStrategyPolicy {
+	if s == nil {
+		return BuildStrategyPolicyOverwrite
+	}
+	if policy, ok := s.Policies[name]; ok {
+		return policy
+	}
+	if s.Policy == "" {
+		return BuildStrategyPolicyOverwrite
+	}
+	return s.Policy
+}
+
+// DeploymentOverride customizes the Deployment of an individual Shipwright component. Fields that
+// are not set keep the values from the upstream release manifests.
+type DeploymentOverride struct {
+	// Replicas is the number of desired pods for the component.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are the compute resources merged into the component's containers.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector is merged into the node selector of the component's pods.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations replaces the tolerations of the component's pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity replaces the scheduling constraints of the component's pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints replaces the topology spread constraints of the component's pods.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the priority class assigned to the component's pods.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// ImageMirror rewrites image references starting with a source prefix to use a mirror prefix
+// instead, similar to an ImageDigestMirrorSet.
+type ImageMirror struct {
+	// Source is the registry or repository prefix to rewrite, e.g. "ghcr.io/shipwright-io".
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// Mirror is the prefix replacing Source, e.g. "registry.example.com/shipwright-io".
+	// +kubebuilder:validation:MinLength=1
+	Mirror string `json:"mirror"`
+}
+
+// BuildSpec defines the desired state of the Shipwright Build components.
+type BuildSpec struct {
+	// Controller customizes the Deployment of the Shipwright Build controller.
+	// +optional
+	Controller *DeploymentOverride `json:"controller,omitempty"`
+
+	// Webhook customizes the Deployment of the Shipwright Build conversion webhook.
+	// +optional
+	Webhook *DeploymentOverride `json:"webhook,omitempty"`
+}
+
 // TriggersSpec defines the desired state of the Triggers component.
 type TriggersSpec struct {
 	// Deployment controls whether the triggers component is deployed.
@@ -29,6 +280,127 @@ type TriggersSpec struct {
 	// +kubebuilder:default=Disabled
 	// +optional
 	Deployment TriggersDeployment `json:"deployment,omitempty"`
+
+	// DeploymentOverride customizes the Deployment of the Shipwright Triggers controller.
+	DeploymentOverride `json:",inline"`
+}
+
+// TektonPrunerSpec configures the Tekton pruner of the created TektonConfig.
+type TektonPrunerSpec struct {
+	// Schedule is the cron schedule of the pruner, e.g. "0 8 * * *". Defaults to the Tekton
+	// Operator schedule.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Keep is the number of resources to keep. Defaults to 100.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Keep *int32 `json:"keep,omitempty"`
+
+	// Resources lists the resources to prune, e.g. "pipelinerun" or "taskrun". Defaults to the
+	// Tekton Operator resources.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+// TektonSpec configures the installation of Tekton Pipelines when it is not installed. An
+// existing TektonConfig is never modified. The profile, target namespace and pruner configure the
+// created TektonConfig, they are rejected when Tekton Pipelines is installed from the release
+// manifest.
+// +kubebuilder:validation:XValidation:rule="!has(self.install) || self.install != 'Pipelines' || (!has(self.profile) && !has(self.targetNamespace) && !has(self.pruner))",message="profile, targetNamespace and pruner are only supported when install is TektonConfig"
+type TektonSpec struct {
+	// Install selects how Tekton Pipelines is installed: with a TektonConfig handled by the Tekton
+	// Operator, or directly from the release manifest embedded in the operator, for clusters
+	// without the Tekton Operator. Defaults to "TektonConfig".
+	// +kubebuilder:default=TektonConfig
+	// +optional
+	Install TektonInstall `json:"install,omitempty"`
+
+	// Manage controls whether the operator creates a TektonConfig. Defaults to "Create".
+	// +kubebuilder:default=Create
+	// +optional
+	Manage TektonManagement `json:"manage,omitempty"`
+
+	// Profile is the Tekton Operator profile, one of "lite", "basic" or "all". Defaults to "lite".
+	// Only used when installing with a TektonConfig.
+	// +kubebuilder:validation:Enum=lite;basic;all
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// TargetNamespace is the namespace where Tekton Pipelines is deployed. Defaults to
+	// "tekton-pipelines". Only used when installing with a TektonConfig, the release manifest
+	// deploys Tekton Pipelines in "tekton-pipelines".
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// Pruner configures the Tekton pruner. Only used when installing with a TektonConfig.
+	// +optional
+	Pruner *TektonPrunerSpec `json:"pruner,omitempty"`
+}
+
+// CertificatesSpec configures the certificates of the Shipwright Build webhook.
+type CertificatesSpec struct {
+	// Mode selects how the webhook certificates are managed: by cert-manager, by the operator
+	// itself, by the OpenShift service CA, or not at all. When omitted, cert-manager is used if
+	// the USE_MANAGED_WEBHOOK_CERTS environment variable of the operator is true, otherwise the
+	// service CA is used on OpenShift, and they are not managed on other platforms.
+	// +optional
+	Mode CertificatesMode `json:"mode,omitempty"`
+
+	// IssuerRef references the cert-manager Issuer or ClusterIssuer issuing the webhook
+	// certificate, e.g. a corporate CA. When omitted, a self-signed Issuer is created in the
+	// target namespace. Only used by the CertManager mode.
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// Duration is the requested validity of the webhook certificate. Defaults to the issuer
+	// default, usually 90 days. Only used by the CertManager mode.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before its expiry the webhook certificate is renewed. Defaults to
+	// a third of its validity. Only used by the CertManager mode.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// PrivateKey configures the private key of the webhook certificate. Only used by the
+	// CertManager mode.
+	// +optional
+	PrivateKey *PrivateKeySpec `json:"privateKey,omitempty"`
+
+	// DNSNames lists additional DNS names of the webhook certificate, besides the name of the
+	// webhook Service.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+// IssuerReference references a cert-manager issuer.
+type IssuerReference struct {
+	// Name is the name of the issuer.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer, either "Issuer" or "ClusterIssuer". Defaults to "Issuer".
+	// +kubebuilder:default=Issuer
+	// +optional
+	Kind IssuerKind `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer, for external issuers. Defaults to "cert-manager.io".
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// PrivateKeySpec configures the private key of a certificate.
+type PrivateKeySpec struct {
+	// Algorithm is the algorithm of the private key. Defaults to the cert-manager default, RSA.
+	// +optional
+	Algorithm PrivateKeyAlgorithm `json:"algorithm,omitempty"`
+
+	// Size is the size of the private key, in bits for RSA or as the curve size for ECDSA.
+	// Defaults to the cert-manager default of the algorithm.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Size int32 `json:"size,omitempty"`
 }
 
 // ShipwrightBuildSpec defines the configuration of a Shipwright Build deployment.
@@ -36,10 +408,158 @@ type ShipwrightBuildSpec struct {
 	// TargetNamespace is the target namespace where Shipwright's build controller will be deployed.
 	TargetNamespace string `json:"targetNamespace,omitempty"`
 
+	// Build configures the deployment of the Shipwright Build controller and webhook.
+	// +optional
+	Build *BuildSpec `json:"build,omitempty"`
+
+	// Tekton configures the installation of Tekton Pipelines, when it is not installed.
+	// +optional
+	Tekton *TektonSpec `json:"tekton,omitempty"`
+
 	// Triggers configures the deployment of the Shipwright Triggers component.
 	// When omitted, triggers are not deployed.
 	// +optional
 	Triggers *TriggersSpec `json:"triggers,omitempty"`
+
+	// Certificates configures the certificates of the Shipwright Build webhook.
+	// +optional
+	Certificates *CertificatesSpec `json:"certificates,omitempty"`
+
+	// BuildStrategies selects the embedded ClusterBuildStrategies to install. When omitted, all
+	// of them are installed.
+	// +optional
+	BuildStrategies *BuildStrategiesSpec `json:"buildStrategies,omitempty"`
+
+	// Images overrides the images of the deployed components. Keys are either container or init
+	// container names (e.g. "shipwright-build"), environment variable names (e.g.
+	// "GIT_CONTAINER_IMAGE") or ClusterBuildStrategy steps in the "<strategy>/<step>" form (e.g.
+	// "buildkit/build-and-push"), matched case-insensitively with "-" and "_" treated as equal.
+	// Entries take precedence over the operator's IMAGE_SHIPWRIGHT_* environment variables.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// ImageMirrors rewrites every image reference rendered by the operator, including component
+	// images, image env vars and ClusterBuildStrategy steps, whose registry or repository matches
+	// one of the sources. The longest matching source wins.
+	// +optional
+	ImageMirrors []ImageMirror `json:"imageMirrors,omitempty"`
+
+	// Proxy sets the egress proxy of the deployed components and of the build strategy steps.
+	// On OpenShift, the fields which are not set are taken from the cluster Proxy.
+	// +optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// TrustedCA adds a CA bundle to the certificates trusted by the Shipwright Build controller and
+	// webhook, and the Triggers controller, to access Git servers and registries using private CAs.
+	// The build strategy steps run in the namespaces of the builds and do not mount it.
+	// +optional
+	TrustedCA *TrustedCASpec `json:"trustedCA,omitempty"`
+
+	// Overrides patches the resources rendered by the operator, for the settings which are not
+	// modeled by the ShipwrightBuild. They are applied in order, after the other settings. Overrides
+	// which are invalid or fail to apply are skipped, and reported on the OverridesApplied
+	// condition.
+	// +optional
+	Overrides []ResourceOverride `json:"overrides,omitempty"`
+
+	// ManagementState tells how the operator manages the components: "Managed" installs and
+	// reconciles them, "Unmanaged" stops applying changes, so that the components can be modified
+	// on the cluster, and "Removed" uninstalls them, keeping the ShipwrightBuild.
+	// +kubebuilder:default=Managed
+	// +optional
+	ManagementState ManagementState `json:"managementState,omitempty"`
+}
+
+// ManagementState is the way the operator manages the components of a ShipwrightBuild.
+// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+type ManagementState string
+
+const (
+	// ManagementStateManaged installs the components and reconciles them.
+	ManagementStateManaged ManagementState = "Managed"
+	// ManagementStateUnmanaged leaves the components as they are on the cluster.
+	ManagementStateUnmanaged ManagementState = "Unmanaged"
+	// ManagementStateRemoved uninstalls the components.
+	ManagementStateRemoved ManagementState = "Removed"
+)
+
+// PatchType is the type of the patch of a ResourceOverride.
+// +kubebuilder:validation:Enum=strategic;merge;json6902
+type PatchType string
+
+const (
+	// PatchTypeStrategic is a Kubernetes strategic merge patch, only supported by the built-in
+	// Kubernetes kinds.
+	PatchTypeStrategic PatchType = "strategic"
+	// PatchTypeMerge is a JSON merge patch (RFC 7386).
+	PatchTypeMerge PatchType = "merge"
+	// PatchTypeJSON6902 is a JSON patch (RFC 6902), a list of operations.
+	PatchTypeJSON6902 PatchType = "json6902"
+)
+
+// ResourceOverride patches the rendered resources matching its target.
+type ResourceOverride struct {
+	// Target selects the resources to patch.
+	Target OverrideTarget `json:"target"`
+
+	// Type is the type of the patch. Defaults to "strategic".
+	// +kubebuilder:default=strategic
+	// +optional
+	Type PatchType `json:"type,omitempty"`
+
+	// Patch is the patch, in YAML or JSON.
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+}
+
+// OverrideTarget selects rendered resources by kind and name.
+type OverrideTarget struct {
+	// Kind is the kind of the resources, e.g. "Deployment".
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name is the name of the resource. When omitted, every resource of the kind is patched.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// TrustedCASpec configures an additional trusted CA bundle.
+type TrustedCASpec struct {
+	// ConfigMap references the ConfigMap holding the PEM encoded CA bundle. On OpenShift, when
+	// omitted, the cluster trusted CA bundle is injected instead.
+	// +optional
+	ConfigMap *ConfigMapKeyReference `json:"configMap,omitempty"`
+}
+
+// ConfigMapKeyReference references an entry of a ConfigMap.
+type ConfigMapKeyReference struct {
+	// Name is the name of the ConfigMap.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap. Defaults to the target namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the entry of the ConfigMap. Defaults to "ca-bundle.crt".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ProxySpec configures an egress proxy, through the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables.
+type ProxySpec struct {
+	// HTTPProxy is the URL of the proxy of the HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy of the HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains and CIDRs which are not proxied.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
 }
 
 // TriggersEnabled returns true if the Triggers component should be deployed.
@@ -51,10 +571,199 @@ func (s *ShipwrightBuildSpec) TriggersEnabled() bool {
 	return s.Triggers.Deployment == TriggersDeploymentEnabled
 }
 
+// TektonPipelinesInstalled returns true if the operator installs Tekton Pipelines from its
+// embedded release manifest, which is only the case when spec.tekton.install is "Pipelines".
+func (s *ShipwrightBuildSpec) TektonPipelinesInstalled() bool {
+	if s.Tekton == nil {
+		return false
+	}
+	return s.Tekton.Install == TektonInstallPipelines
+}
+
+// EffectiveManagementState returns the management state of the components, "Managed" when it is
+// not informed.
+func (s *ShipwrightBuildSpec) EffectiveManagementState() ManagementState {
+	if s.ManagementState == "" {
+		return ManagementStateManaged
+	}
+	return s.ManagementState
+}
+
+// ComponentVersions reports the versions of the deployed components, as found in the tag (or
+// digest) of their images.
+type ComponentVersions struct {
+	// Build is the version of Shipwright Build.
+	// +optional
+	Build string `json:"build,omitempty"`
+
+	// Triggers is the version of Shipwright Triggers. Empty when Triggers are not deployed.
+	// +optional
+	Triggers string `json:"triggers,omitempty"`
+}
+
+// ResourceReference identifies a resource applied by the operator.
+type ResourceReference struct {
+	// APIVersion is the API version of the resource.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the resource. Empty for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the resource.
+	Name string `json:"name"`
+}
+
 // ShipwrightBuildStatus defines the observed state of ShipwrightBuild
 type ShipwrightBuildStatus struct {
 	// Conditions holds the latest available observations of a resource's current state.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of the ShipwrightBuild reflected by this status.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Versions reports the versions of the deployed components.
+	// +optional
+	Versions ComponentVersions `json:"versions,omitempty"`
+
+	// AppliedResources lists the resources applied by the operator during the last successful
+	// reconcile.
+	// +optional
+	AppliedResources []ResourceReference `json:"appliedResources,omitempty"`
+
+	// Images lists the effective images of the deployed components and build strategies, keyed
+	// by the normalized container, environment variable or "<strategy>/<step>" name.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// BuildStrategies lists the names of the installed ClusterBuildStrategies.
+	// +optional
+	BuildStrategies []string `json:"buildStrategies,omitempty"`
+
+	// BuildStrategySources reports the ClusterBuildStrategies loaded from each source.
+	// +optional
+	BuildStrategySources []BuildStrategySourceStatus `json:"buildStrategySources,omitempty"`
+
+	// Retry reports when the reconciliation is retried, while it is failing or waiting for a
+	// precondition.
+	// +optional
+	Retry *RetryStatus `json:"retry,omitempty"`
+
+	// Certificates reports the webhook certificate, when it is managed by the operator.
+	// +optional
+	Certificates *CertificatesStatus `json:"certificates,omitempty"`
+
+	// Platform reports the platform the operator runs on, and its capabilities.
+	// +optional
+	Platform *PlatformStatus `json:"platform,omitempty"`
+
+	// Plan reports the changes the reconciliation would make to the cluster, computed instead of
+	// applying them while the ShipwrightBuild has the "operator.shipwright.io/dry-run" annotation.
+	// +optional
+	Plan *PlanStatus `json:"plan,omitempty"`
+
+	// ManagementState is the effective management state of the components.
+	// +optional
+	ManagementState ManagementState `json:"managementState,omitempty"`
+}
+
+// PlanStatus describes the changes the reconciliation would make to the cluster.
+type PlanStatus struct {
+	// GeneratedTime is the time at which the plan was computed.
+	GeneratedTime metav1.Time `json:"generatedTime"`
+
+	// Creates is the number of resources which would be created.
+	Creates int32 `json:"creates"`
+
+	// Updates is the number of resources which would be updated.
+	Updates int32 `json:"updates"`
+
+	// Deletes is the number of resources which would be deleted.
+	Deletes int32 `json:"deletes"`
+
+	// Changes lists the resources which would be created, updated or deleted.
+	// +optional
+	Changes []PlannedChange `json:"changes,omitempty"`
+}
+
+// PlanAction is the action the reconciliation would take on a resource.
+// +kubebuilder:validation:Enum=Create;Update;Delete
+type PlanAction string
+
+const (
+	// PlanActionCreate creates a resource which does not exist.
+	PlanActionCreate PlanAction = "Create"
+	// PlanActionUpdate updates a resource which differs from the rendered one.
+	PlanActionUpdate PlanAction = "Update"
+	// PlanActionDelete deletes a resource which is no longer rendered.
+	PlanActionDelete PlanAction = "Delete"
+)
+
+// PlannedChange describes the change the reconciliation would make to a resource.
+type PlannedChange struct {
+	// Action is the action taken on the resource.
+	Action PlanAction `json:"action"`
+
+	ResourceReference `json:",inline"`
+
+	// Fields lists the paths of the fields which would be updated, such as
+	// "spec.template.spec.containers".
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+
+	// Error is the error returned by the API server for the dry-run of the change, which would
+	// fail to be applied.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PlatformStatus describes the platform the operator runs on.
+type PlatformStatus struct {
+	// Name is the name of the platform, either "OpenShift" or "Kubernetes".
+	Name string `json:"name"`
+
+	// Capabilities lists the optional features detected on the platform, such as "Routes",
+	// "SecurityContextConstraints", "ServiceCA", "PrometheusOperator", "CertManager" or
+	// "GatewayAPI".
+	// +optional
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// CertificatesStatus describes the webhook certificate.
+type CertificatesStatus struct {
+	// Issuer is the issuer of the certificate, in the "<kind>/<name>" form, "BuiltIn" when the
+	// certificate is generated by the operator, or "ServiceCA" when it is issued by the OpenShift
+	// service CA.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Ready tells whether the certificate is issued and valid.
+	Ready bool `json:"ready"`
+
+	// NotAfter is the expiry of the certificate.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// RenewalTime is the time at which the certificate is renewed.
+	// +optional
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+}
+
+// RetryStatus describes the backoff of a failing or waiting reconciliation.
+type RetryStatus struct {
+	// Reason is the class of the cause of the retry: Waiting, TransientError or
+	// ConfigurationError.
+	Reason string `json:"reason"`
+
+	// Attempts is the number of consecutive retries.
+	Attempts int32 `json:"attempts"`
+
+	// NextRetryTime is the time of the next retry.
+	NextRetryTime metav1.Time `json:"nextRetryTime"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/pkg/buildstrategy"
+	"github.com/shipwright-io/operator/pkg/certificates"
+	"github.com/shipwright-io/operator/pkg/certmanager"
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+// dryRun returns true when the ShipwrightBuild asks for the changes to be planned instead of
+// applied.
+func dryRun(b *v1alpha1.ShipwrightBuild) bool {
+	return b.GetAnnotations()[DryRunAnnotation] == "true"
+}
+
+// plan computes the changes the reconciliation of the ShipwrightBuild would make to the cluster,
+// without applying them: the rendered resources which would be created or updated, and the ones
+// which would be deleted, such as the unselected build strategies or the disabled triggers. In the
+// Removed management state, the plan deletes the installed components.
+func (r *ShipwrightBuildReconciler) plan(ctx context.Context, b *v1alpha1.ShipwrightBuild) (*v1alpha1.PlanStatus, error) {
+	targetNamespace := effectiveTargetNamespace(b)
+	p := &planner{}
+	if b.Spec.EffectiveManagementState() == v1alpha1.ManagementStateRemoved {
+		manifests, err := r.uninstallManifests(ctx, b, targetNamespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range manifests {
+			p.delete(manifest, nil)
+		}
+		return p.status()
+	}
+
+	s := r.newRenderSettings(b, targetNamespace)
+	var err error
+	if s.proxy, err = r.proxy(ctx, b); err != nil {
+		return nil, err
+	}
+	if s.trustedCAConfigMap, s.trustedCAHash, err = r.trustedCA(ctx, b, targetNamespace); err != nil {
+		return nil, err
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+	p.apply(r.objectsManifest(namespace))
+	if b.Spec.TektonPipelinesInstalled() {
+		p.apply(r.renderTektonPipelines(b, s.proxy))
+	}
+	switch s.certificatesMode {
+	case v1alpha1.CertificatesModeCertManager:
+		p.apply(certmanager.Manifest(r.Client, r.Logger, targetNamespace, b.Spec.Certificates))
+	case v1alpha1.CertificatesModeBuiltIn:
+		dnsNames := certificates.WebhookDNSNames(targetNamespace)
+		if b.Spec.Certificates != nil {
+			dnsNames = append(dnsNames, b.Spec.Certificates.DNSNames...)
+		}
+		// the certificates are generated when missing or due for renewal, and recorded by the
+		// planning client instead of being stored
+		c := &planClient{Client: r.Client}
+		s.builtInCertificates, err = certificates.ReconcileBuiltIn(ctx, r.uncachedReader(), c, targetNamespace,
+			dnsNames, map[string]string{ShipwrightBuildLabel: b.Name}, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		p.changes = append(p.changes, c.changes...)
+	}
+
+	p.apply(r.renderBuild(b, s))
+	p.delete(r.objectsManifest(
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "shipwright-build-webhook"}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "shipwright-build-webhook"}},
+	))
+	if s.trustedCAConfigMap == nil {
+		p.delete(r.objectsManifest(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: common.TrustedCAConfigMap}}))
+	}
+
+	sourceStrategies, sourceStatuses := buildstrategy.LoadSources(ctx, r.uncachedReader(), b.Spec.BuildStrategies,
+		targetNamespace, buildstrategy.BuildStrategyNames(r.BuildStrategyManifest))
+	sourceManifest, err := manifestival.ManifestFrom(manifestival.Slice(sourceStrategies))
+	if err != nil {
+		return nil, err
+	}
+	buildStrategyManifest, err := r.renderBuildStrategies(b, s, sourceManifest)
+	if err != nil {
+		return nil, err
+	}
+	install, remove, err := buildstrategy.PlanBuildStrategies(buildStrategyManifest, b.Spec.BuildStrategies)
+	if err != nil {
+		return nil, err
+	}
+	p.apply(install, nil)
+	p.delete(remove, nil)
+	pruned, err := buildstrategy.PrunedSourceStrategies(ctx, r.uncachedReader(), buildStrategyManifest, sourceStatuses, b.Spec.BuildStrategies)
+	if err != nil {
+		return nil, err
+	}
+	p.delete(manifestival.ManifestFrom(manifestival.Slice(pruned), manifestival.UseClient(r.Manifest.Client)))
+
+	triggersManifest, err := r.renderTriggers(b, s)
+	if b.Spec.TriggersEnabled() {
+		p.apply(triggersManifest, err)
+	} else {
+		p.delete(triggersManifest, err)
+	}
+	return p.status()
+}
+
+// objectsManifest returns a manifest of the given objects, using the client of the release
+// manifest.
+func (r *ShipwrightBuildReconciler) objectsManifest(objects ...client.Object) (manifestival.Manifest, error) {
+	resources := []unstructured.Unstructured{}
+	for _, obj := range objects {
+		gvk, err := apiutil.GVKForObject(obj, r.Scheme)
+		if err != nil {
+			return manifestival.Manifest{}, err
+		}
+		u := unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		u.SetNamespace(obj.GetNamespace())
+		u.SetName(obj.GetName())
+		resources = append(resources, u)
+	}
+	return manifestival.ManifestFrom(manifestival.Slice(resources), manifestival.UseClient(r.Manifest.Client))
+}
+
+// planner collects the planned changes of manifests, keeping the first error.
+type planner struct {
+	changes []v1alpha1.PlannedChange
+	err     error
+}
+
+// apply plans the creates and updates applying the manifest would make.
+func (p *planner) apply(manifest manifestival.Manifest, err error) {
+	p.add(manifest, err, common.PlanApply)
+}
+
+// delete plans the deletion of the resources of the manifest which exist.
+func (p *planner) delete(manifest manifestival.Manifest, err error) {
+	p.add(manifest, err, common.PlanDelete)
+}
+
+// status returns the plan of the collected changes, or the first error.
+func (p *planner) status() (*v1alpha1.PlanStatus, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	creates, updates, deletes := common.PlanCounts(p.changes)
+	return &v1alpha1.PlanStatus{
+		GeneratedTime: metav1.NewTime(time.Now().Truncate(time.Second)),
+		Creates:       creates,
+		Updates:       updates,
+		Deletes:       deletes,
+		Changes:       p.changes,
+	}, nil
+}
+
+func (p *planner) add(manifest manifestival.Manifest, err error, plan func(manifestival.Manifest) ([]v1alpha1.PlannedChange, error)) {
+	if p.err != nil {
+		return
+	}
+	if err != nil {
+		p.err = err
+		return
+	}
+	changes, err := plan(manifest)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.changes = append(p.changes, changes...)
+}
+
+// planClient records the objects created and updated through it as planned changes, and only
+// submits them to the API server as dry-runs.
+type planClient struct {
+	client.Client
+	changes []v1alpha1.PlannedChange
+}
+
+func (c *planClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...)
+	return c.record(v1alpha1.PlanActionCreate, obj, err)
+}
+
+func (c *planClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...)
+	return c.record(v1alpha1.PlanActionUpdate, obj, err)
+}
+
+func (c *planClient) record(action v1alpha1.PlanAction, obj client.Object, err error) error {
+	gvk, gvkErr := apiutil.GVKForObject(obj, c.Scheme())
+	if gvkErr != nil {
+		return gvkErr
+	}
+	change := v1alpha1.PlannedChange{
+		Action: action,
+		ResourceReference: v1alpha1.ResourceReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		},
+	}
+	if err != nil {
+		change.Error = err.Error()
+	}
+	c.changes = append(c.changes, change)
+	return nil
+}
+
+// planMessage summarizes the plan for the DryRun condition.
+func planMessage(plan *v1alpha1.PlanStatus) string {
+	message := fmt.Sprintf("Changes are not applied: %d to create, %d to update, %d to delete",
+		plan.Creates, plan.Updates, plan.Deletes)
+	failing := 0
+	for _, change := range plan.Changes {
+		if change.Error != "" {
+			failing++
+		}
+	}
+	if failing > 0 {
+		message += fmt.Sprintf(", %d of which would fail", failing)
+	}
+	return message
+}
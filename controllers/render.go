@@ -0,0 +1,316 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/pkg/buildstrategy"
+	"github.com/shipwright-io/operator/pkg/certificates"
+	"github.com/shipwright-io/operator/pkg/certmanager"
+	"github.com/shipwright-io/operator/pkg/common"
+	"github.com/shipwright-io/operator/pkg/platform"
+)
+
+const (
+	// placeholderCABundle is rendered in place of the CA bundle of the built-in webhook
+	// certificates, when their Secret is not given to Render.
+	placeholderCABundle = "placeholder"
+	// placeholderCertificateHash is rendered in place of the hash of the built-in webhook serving
+	// certificate, when its Secret is not given to Render.
+	placeholderCertificateHash = "placeholder"
+)
+
+// renderSettings holds the settings of the rendered manifests, resolved from the ShipwrightBuild
+// and from the cluster.
+type renderSettings struct {
+	targetNamespace     string
+	images              map[string]string
+	deploymentOverrides map[string]*v1alpha1.DeploymentOverride
+	proxy               *v1alpha1.ProxySpec
+	trustedCAConfigMap  *unstructured.Unstructured // nil when no trusted CA is configured
+	trustedCAHash       string
+	certificatesMode    v1alpha1.CertificatesMode
+	builtInCertificates *certificates.BuiltIn // set in the BuiltIn certificates mode
+	overrides           *common.Overrides
+}
+
+// newRenderSettings resolves the settings of the manifests which only depend on the
+// ShipwrightBuild spec.
+func (r *ShipwrightBuildReconciler) newRenderSettings(b *v1alpha1.ShipwrightBuild, targetNamespace string) *renderSettings {
+	return &renderSettings{
+		targetNamespace:     targetNamespace,
+		images:              common.MergeImages(common.ToLowerCaseKeys(common.ImagesFromEnv(common.ShipwrightImagePrefix)), b.Spec.Images),
+		deploymentOverrides: common.ComponentDeploymentOverrides(&b.Spec),
+		certificatesMode:    r.certificatesMode(b),
+		overrides:           common.NewOverrides(b.Spec.Overrides),
+	}
+}
+
+// renderTektonPipelines renders the embedded Tekton Pipelines release manifest.
+func (r *ShipwrightBuildReconciler) renderTektonPipelines(b *v1alpha1.ShipwrightBuild, proxy *v1alpha1.ProxySpec) (manifestival.Manifest, error) {
+	return r.TektonManifest.Transform(
+		common.TruncateCRDFieldTransformer("description", 50),
+		common.ImageMirrors(b.Spec.ImageMirrors),
+		common.InjectProxy(proxy),
+		common.InjectLabels(ShipwrightBuildLabel, b.Name),
+	)
+}
+
+// renderBuild renders the Shipwright Build release manifest, along with the trusted CA ConfigMap.
+func (r *ShipwrightBuildReconciler) renderBuild(b *v1alpha1.ShipwrightBuild, s *renderSettings) (manifestival.Manifest, error) {
+	// Applying transformers
+	// image transformers: Alow to inject custom component images and rewrite them to registry mirrors
+	// namespace transformer: Allow installing in a specific namespace
+	// deployment overrides transformer: Allow tuning replicas, resources and scheduling per component
+	// certificates transformers: inject the CA of the webhook certificates, depending on their mode
+	// trusted CA transformer: mount the trusted CA bundle in the components
+	// overrides transformer: patch the rendered resources, after all the other transformers
+	transformerfncs := []manifestival.Transformer{}
+	transformerfncs = append(transformerfncs, common.TruncateCRDFieldTransformer("description", 50))
+	transformerfncs = append(transformerfncs, manifestival.InjectNamespace(s.targetNamespace))
+	transformerfncs = append(transformerfncs, common.DeploymentImages(s.images))
+	if s.trustedCAConfigMap != nil {
+		transformerfncs = append(transformerfncs, common.TrustedCA(s.trustedCAHash))
+	}
+	transformerfncs = append(transformerfncs, common.ImageMirrors(b.Spec.ImageMirrors))
+	transformerfncs = append(transformerfncs, common.DeploymentOverrides(s.deploymentOverrides))
+	transformerfncs = append(transformerfncs, common.InjectProxy(s.proxy))
+	transformerfncs = append(transformerfncs, common.InjectLabels(ShipwrightBuildLabel, b.Name))
+	switch {
+	case s.certificatesMode == v1alpha1.CertificatesModeBuiltIn:
+		transformerfncs = append(transformerfncs, certificates.InjectCABundle(s.builtInCertificates.CABundle))
+		transformerfncs = append(transformerfncs, certificates.InjectCertificateHash(common.BuildWebhookDeployment, s.builtInCertificates.Hash))
+	case s.certificatesMode == v1alpha1.CertificatesModeServiceCA:
+		transformerfncs = append(transformerfncs, certificates.InjectServiceCA())
+	case s.certificatesMode == v1alpha1.CertificatesModeCertManager || !r.platform().OpenShift:
+		transformerfncs = append(transformerfncs, common.InjectAnnotations(CertManagerInjectAnnotationKey, fmt.Sprintf(CertManagerInjectAnnotationValueTemplate, s.targetNamespace), common.Overwrite, "CustomResourceDefinition"))
+	}
+	transformerfncs = append(transformerfncs, s.overrides.Transformer())
+
+	manifest := r.Manifest
+	if s.trustedCAConfigMap != nil {
+		trustedCAManifest, err := manifestival.ManifestFrom(manifestival.Slice([]unstructured.Unstructured{*s.trustedCAConfigMap}))
+		if err != nil {
+			return manifestival.Manifest{}, err
+		}
+		manifest = manifest.Append(trustedCAManifest)
+	}
+	return manifest.
+		Filter(manifestival.Not(manifestival.ByKind("Namespace"))).
+		Transform(transformerfncs...)
+}
+
+// renderBuildStrategies renders the embedded ClusterBuildStrategies, along with the ones loaded
+// from the sources.
+func (r *ShipwrightBuildReconciler) renderBuildStrategies(b *v1alpha1.ShipwrightBuild, s *renderSettings, sources manifestival.Manifest) (manifestival.Manifest, error) {
+	transformers := []manifestival.Transformer{
+		common.BuildStrategyImages(s.images),
+		common.ImageMirrors(b.Spec.ImageMirrors),
+		common.InjectProxy(s.proxy),
+		common.InjectLabels(ShipwrightBuildLabel, b.Name),
+		s.overrides.Transformer(),
+		buildstrategy.ContentHash(),
+	}
+	return r.BuildStrategyManifest.Append(sources).Transform(transformers...)
+}
+
+// renderTriggers renders the Shipwright Triggers release manifest.
+func (r *ShipwrightBuildReconciler) renderTriggers(b *v1alpha1.ShipwrightBuild, s *renderSettings) (manifestival.Manifest, error) {
+	transformers := []manifestival.Transformer{
+		// TODO: Remove this when we remove the target namespace feature.
+		// See https://github.com/shipwright-io/operator/issues/241
+		manifestival.InjectNamespace(s.targetNamespace),
+		common.DeploymentImages(s.images),
+	}
+	if s.trustedCAConfigMap != nil {
+		transformers = append(transformers, common.TrustedCA(s.trustedCAHash))
+	}
+	transformers = append(transformers,
+		common.ImageMirrors(b.Spec.ImageMirrors),
+		common.DeploymentOverrides(s.deploymentOverrides),
+		common.InjectProxy(s.proxy),
+		common.InjectLabels(ShipwrightBuildLabel, b.Name),
+		s.overrides.Transformer(),
+	)
+	return r.TriggersManifest.
+		Filter(manifestival.Not(manifestival.ByKind("Namespace"))).
+		Transform(transformers...)
+}
+
+// Render returns the resources applied by the reconciliation of the ShipwrightBuild on the given
+// platform, without a cluster connection. The objects the ShipwrightBuild refers to, such as the
+// trusted CA ConfigMap, the build strategy sources, the cluster Proxy or the built-in webhook
+// certificates Secret, are read from the given objects instead of the cluster. The built-in webhook
+// certificates are not generated, so that the resources are the same on every call: the ones of
+// the given Secret are used, or placeholders when it is not given. The returned errors are the
+// ones of the overrides which were skipped.
+func Render(ctx context.Context, b *v1alpha1.ShipwrightBuild, p *platform.Platform, scheme *runtime.Scheme, objects []client.Object, logger logr.Logger) ([]unstructured.Unstructured, []error, error) {
+	reader := &objectReader{scheme: scheme, objects: objects}
+	// the manifests are only rendered, they have no client to be applied with
+	r := &ShipwrightBuildReconciler{APIReader: reader, Scheme: scheme, Platform: p, Logger: logger}
+	if err := r.setupManifestival(); err != nil {
+		return nil, nil, err
+	}
+
+	targetNamespace := effectiveTargetNamespace(b)
+	s := r.newRenderSettings(b, targetNamespace)
+	var err error
+	if s.proxy, err = r.proxy(ctx, b); err != nil {
+		return nil, nil, err
+	}
+	if s.trustedCAConfigMap, s.trustedCAHash, err = r.trustedCA(ctx, b, targetNamespace); err != nil {
+		return nil, nil, err
+	}
+
+	resources := []unstructured.Unstructured{}
+	if b.Spec.TektonPipelinesInstalled() {
+		tektonManifest, err := r.renderTektonPipelines(b, s.proxy)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, tektonManifest.Resources()...)
+	}
+	switch s.certificatesMode {
+	case v1alpha1.CertificatesModeCertManager:
+		certificatesManifest, err := certmanager.Manifest(r.Client, logger, targetNamespace, b.Spec.Certificates)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, certificatesManifest.Resources()...)
+	case v1alpha1.CertificatesModeBuiltIn:
+		s.builtInCertificates, err = certificates.GetBuiltIn(ctx, reader, targetNamespace)
+		if errors.IsNotFound(err) {
+			s.builtInCertificates, err = &certificates.BuiltIn{
+				CABundle: []byte(placeholderCABundle),
+				Hash:     placeholderCertificateHash,
+			}, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	buildManifest, err := r.renderBuild(b, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, buildManifest.Resources()...)
+
+	sourceStrategies, sourceStatuses := buildstrategy.LoadSources(ctx, reader, b.Spec.BuildStrategies,
+		targetNamespace, buildstrategy.BuildStrategyNames(r.BuildStrategyManifest))
+	for _, status := range sourceStatuses {
+		if status.Error != "" {
+			return nil, nil, fmt.Errorf("loading cluster build strategies from %s %s/%s: %s", status.Kind, status.Namespace, status.Name, status.Error)
+		}
+	}
+	sourceManifest, err := manifestival.ManifestFrom(manifestival.Slice(sourceStrategies))
+	if err != nil {
+		return nil, nil, err
+	}
+	buildStrategyManifest, err := r.renderBuildStrategies(b, s, sourceManifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	installedStrategies, _ := buildstrategy.SelectBuildStrategies(buildStrategyManifest, b.Spec.BuildStrategies)
+	resources = append(resources, installedStrategies.Resources()...)
+
+	if b.Spec.TriggersEnabled() {
+		triggersManifest, err := r.renderTriggers(b, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, triggersManifest.Resources()...)
+	}
+	return resources, s.overrides.Errors(), nil
+}
+
+// objectReader is a read-only client.Reader over the given objects, which Render reads instead of
+// the cluster.
+type objectReader struct {
+	scheme  *runtime.Scheme
+	objects []client.Object
+}
+
+// Get copies the object of the kind of obj, with the namespace and name of the key, into obj.
+func (o *objectReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, o.scheme)
+	if err != nil {
+		return err
+	}
+	for _, object := range o.objects {
+		content, err := o.content(object, gvk)
+		if err != nil {
+			return err
+		}
+		if content != nil && object.GetNamespace() == key.Namespace && object.GetName() == key.Name {
+			return decode(content, gvk, obj)
+		}
+	}
+	return errors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: strings.ToLower(gvk.Kind)}, key.Name)
+}
+
+// List copies the objects of the kind of the list items, selected by the options, into the list.
+func (o *objectReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listGVK, err := apiutil.GVKForObject(list, o.scheme)
+	if err != nil {
+		return err
+	}
+	gvk := listGVK.GroupVersion().WithKind(strings.TrimSuffix(listGVK.Kind, "List"))
+	options := (&client.ListOptions{}).ApplyOptions(opts)
+	items := []interface{}{}
+	for _, object := range o.objects {
+		content, err := o.content(object, gvk)
+		if err != nil {
+			return err
+		}
+		if content == nil || (options.Namespace != "" && object.GetNamespace() != options.Namespace) {
+			continue
+		}
+		if options.LabelSelector != nil && !options.LabelSelector.Matches(labels.Set(object.GetLabels())) {
+			continue
+		}
+		items = append(items, content)
+	}
+	return decode(map[string]interface{}{"items": items}, listGVK, list)
+}
+
+// content returns a copy of the unstructured content of the object when it is of the given kind,
+// or nil otherwise.
+func (o *objectReader) content(object client.Object, gvk schema.GroupVersionKind) (map[string]interface{}, error) {
+	objectGVK, err := apiutil.GVKForObject(object, o.scheme)
+	if err != nil {
+		return nil, err
+	}
+	if objectGVK != gvk {
+		return nil, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	content = runtime.DeepCopyJSON(content)
+	content["apiVersion"], content["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	return content, nil
+}
+
+// decode sets the unstructured content on the object, typed or unstructured, of the given kind.
+func decode(content map[string]interface{}, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
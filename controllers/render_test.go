@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	o "github.com/onsi/gomega"
+	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/apis/operator/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/pkg/certificates"
+	"github.com/shipwright-io/operator/pkg/common"
+	"github.com/shipwright-io/operator/pkg/platform"
+)
+
+// renderedDeployment returns the named Deployment of the rendered resources.
+func renderedDeployment(g *o.WithT, resources []unstructured.Unstructured, name string) *appsv1.Deployment {
+	for _, u := range resources {
+		if u.GetKind() == "Deployment" && u.GetName() == name {
+			d := &appsv1.Deployment{}
+			g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)).To(o.Succeed())
+			return d
+		}
+	}
+	g.Expect(name).To(o.BeEmpty(), "Deployment is not rendered")
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Images:          map[string]string{"shipwright-build": "registry.example.com/build-controller:v1"},
+			Proxy:           &v1alpha1.ProxySpec{HTTPSProxy: "http://proxy:3128"},
+			TrustedCA: &v1alpha1.TrustedCASpec{
+				ConfigMap: &v1alpha1.ConfigMapKeyReference{Name: "corporate-ca"},
+			},
+			Triggers: &v1alpha1.TriggersSpec{Deployment: v1alpha1.TriggersDeploymentEnabled},
+			Overrides: []v1alpha1.ResourceOverride{{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment", Name: common.BuildControllerDeployment},
+				Type:   v1alpha1.PatchTypeMerge,
+				Patch:  `{"spec": {"template": {"spec": {"priorityClassName": "system-cluster-critical"}}}}`,
+			}, {
+				Target: v1alpha1.OverrideTarget{Kind: "ClusterBuildStrategy"},
+				Patch:  `{"metadata": {"labels": {"patched": "true"}}}`,
+			}},
+		},
+	}
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "corporate-ca"},
+		Data:       map[string]string{common.TrustedCAKey: "bundle"},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b.DeepCopy(), tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	g.Expect(c.Create(ctx, source.DeepCopy())).To(o.Succeed())
+
+	_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}})
+	g.Expect(err).To(o.BeNil())
+
+	resources, skipped, err := Render(ctx, b, &platform.Platform{Capabilities: platform.Capabilities{}}, r.Scheme, []client.Object{source}, zap.New())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(skipped).To(o.ConsistOf(o.MatchError(o.ContainSubstring("strategic patches are not supported by ClusterBuildStrategy"))))
+	g.Expect(resources).To(o.ContainElement(o.WithTransform(func(u unstructured.Unstructured) string {
+		return u.GetKind() + "/" + u.GetNamespace() + "/" + u.GetName()
+	}, o.Equal("ConfigMap/namespace/"+common.TrustedCAConfigMap))))
+
+	// the rendered Deployments match the ones applied by the reconciliation, which waits for the
+	// Triggers preconditions before applying them
+	renderedDeployment(g, resources, common.TriggersDeployment)
+	for _, name := range []string{common.BuildControllerDeployment, common.BuildWebhookDeployment} {
+		applied := &appsv1.Deployment{}
+		g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: name}, applied)).To(o.Succeed())
+		rendered := renderedDeployment(g, resources, name)
+		g.Expect(rendered.Labels).To(o.Equal(applied.Labels), name)
+		g.Expect(rendered.Spec.Template).To(o.Equal(applied.Spec.Template), name)
+	}
+	d := renderedDeployment(g, resources, common.BuildControllerDeployment)
+	g.Expect(d.Spec.Template.Spec.PriorityClassName).To(o.Equal("system-cluster-critical"))
+	g.Expect(d.Spec.Template.Spec.Containers[0].Image).To(o.Equal("registry.example.com/build-controller:v1"))
+}
+
+func TestRenderBuiltInCertificates(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(s)).To(o.Succeed())
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Certificates:    &v1alpha1.CertificatesSpec{Mode: v1alpha1.CertificatesModeBuiltIn},
+		},
+	}
+	p := &platform.Platform{Capabilities: platform.Capabilities{}}
+	webhookHash := func(resources []unstructured.Unstructured) string {
+		return renderedDeployment(g, resources, common.BuildWebhookDeployment).Spec.Template.Annotations[certificates.CertificateHashAnnotation]
+	}
+
+	// placeholders are rendered when the Secret is not given, the same on every call
+	resources, _, err := Render(ctx, b, p, s, nil, zap.New())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(webhookHash(resources)).To(o.Equal(placeholderCertificateHash))
+	again, _, err := Render(ctx, b, p, s, nil, zap.New())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(again).To(o.Equal(resources))
+
+	// the certificates of the given Secret are rendered, without being rotated
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	certs, err := certificates.ReconcileBuiltIn(ctx, c, c, "namespace", certificates.WebhookDNSNames("namespace"), nil, time.Now())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	secret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: certificates.WebhookSecret}, secret)).To(o.Succeed())
+	resources, _, err = Render(ctx, b, p, s, []client.Object{secret}, zap.New())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(webhookHash(resources)).To(o.Equal(certs.Hash))
+}
+
+func TestObjectReader(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(s)).To(o.Succeed())
+	labeled := &unstructured.Unstructured{}
+	labeled.SetAPIVersion("v1")
+	labeled.SetKind("ConfigMap")
+	labeled.SetNamespace("namespace")
+	labeled.SetName("labeled")
+	labeled.SetLabels(map[string]string{"source": "true"})
+	reader := &objectReader{scheme: s, objects: []client.Object{
+		labeled,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "typed"}, Data: map[string]string{"key": "value"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "typed"}},
+	}}
+
+	// objects are read as typed or unstructured objects, whatever they are given as
+	configMap := &corev1.ConfigMap{}
+	g.Expect(reader.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: "labeled"}, configMap)).To(o.Succeed())
+	g.Expect(configMap.Labels).To(o.HaveKeyWithValue("source", "true"))
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	g.Expect(reader.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: "typed"}, u)).To(o.Succeed())
+	g.Expect(u.Object).To(o.HaveKeyWithValue("data", map[string]interface{}{"key": "value"}))
+	err := reader.Get(ctx, types.NamespacedName{Namespace: "other", Name: "typed"}, configMap)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+
+	list := &corev1.ConfigMapList{}
+	g.Expect(reader.List(ctx, list, client.InNamespace("namespace"))).To(o.Succeed())
+	g.Expect(list.Items).To(o.HaveLen(2))
+	g.Expect(reader.List(ctx, list, client.HasLabels{"source"})).To(o.Succeed())
+	g.Expect(list.Items).To(o.HaveLen(1))
+	g.Expect(list.Items[0].Name).To(o.Equal("labeled"))
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Backoff is an exponential backoff, starting at Delay and doubling on every attempt, up to
+// MaxDelay.
+type Backoff struct {
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// delay returns the delay before the given attempt, counted from zero, with up to the given
+// jitter factor added to it. The jittered delay never exceeds MaxDelay.
+func (b Backoff) delay(attempt int, jitter float64) time.Duration {
+	d := b.Delay
+	for i := 0; i < attempt && d < b.MaxDelay; i++ {
+		d *= 2
+	}
+	if jitter > 0 {
+		d = wait.Jitter(d, jitter)
+	}
+	return min(d, b.MaxDelay)
+}
+
+// RequeuePolicy backs off the requeues of the objects which could not be reconciled, with the
+// backoff of the reason of the requeue. Attempts are counted per object, until it is reconciled
+// successfully.
+//
+// RequeuePolicy is also the rate limiter of the controller work queue, so that the requeues caused
+// by reconciliation errors are delayed by the policy as well. The attempts of the objects the work
+// queue forgot while they were requeued after a delay are restored from their status by Restore.
+type RequeuePolicy struct {
+	Backoffs map[RequeueReason]Backoff // backoff of each requeue reason
+	Jitter   float64                   // maximum jitter factor added to the delays
+
+	mutex    sync.Mutex
+	attempts map[reconcile.Request]int           // consecutive requeues of each object
+	delays   map[reconcile.Request]time.Duration // delay of the next requeue of each object
+}
+
+// DefaultBackoffs returns the default backoff of each requeue reason.
+func DefaultBackoffs() map[RequeueReason]Backoff {
+	return map[RequeueReason]Backoff{
+		RequeueWaiting:            {Delay: 5 * time.Second, MaxDelay: 5 * time.Minute},
+		RequeueTransientError:     {Delay: 1 * time.Second, MaxDelay: 5 * time.Minute},
+		RequeueConfigurationError: {Delay: 30 * time.Second, MaxDelay: 30 * time.Minute},
+	}
+}
+
+// NewRequeuePolicy instantiates a RequeuePolicy with the given backoffs, falling back to the
+// default backoff for the reasons that are missing.
+func NewRequeuePolicy(backoffs map[RequeueReason]Backoff, jitter float64) *RequeuePolicy {
+	policy := &RequeuePolicy{
+		Backoffs: DefaultBackoffs(),
+		Jitter:   jitter,
+		attempts: map[reconcile.Request]int{},
+		delays:   map[reconcile.Request]time.Duration{},
+	}
+	for reason, backoff := range backoffs {
+		policy.Backoffs[reason] = backoff
+	}
+	return policy
+}
+
+// Next records a requeue of the object for the given reason, and returns its delay together with
+// the number of consecutive requeues of the object.
+func (p *RequeuePolicy) Next(req reconcile.Request, reason RequeueReason) (time.Duration, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	backoff, exists := p.Backoffs[reason]
+	if !exists {
+		backoff = p.Backoffs[RequeueTransientError]
+	}
+	attempt := p.attempts[req]
+	delay := backoff.delay(attempt, p.Jitter)
+	p.attempts[req] = attempt + 1
+	p.delays[req] = delay
+	return delay, attempt + 1
+}
+
+// Restore resumes the count of consecutive requeues of the object from the given attempts, such as
+// the ones reported on its status, when the policy has forgotten them.
+func (p *RequeuePolicy) Restore(req reconcile.Request, attempts int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.attempts[req] = max(p.attempts[req], attempts)
+}
+
+// When returns the delay of the next requeue of the object, recorded by Next, or the transient
+// error backoff when none is recorded.
+func (p *RequeuePolicy) When(req reconcile.Request) time.Duration {
+	p.mutex.Lock()
+	delay, exists := p.delays[req]
+	p.mutex.Unlock()
+	if exists {
+		return delay
+	}
+	delay, _ = p.Next(req, RequeueTransientError)
+	return delay
+}
+
+// Forget forgets the requeues of the object. The work queue forgets the objects which are
+// reconciled successfully or requeued after a delay.
+func (p *RequeuePolicy) Forget(req reconcile.Request) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.attempts, req)
+	delete(p.delays, req)
+}
+
+// NumRequeues returns the number of consecutive requeues of the object.
+func (p *RequeuePolicy) NumRequeues(req reconcile.Request) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.attempts[req]
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	o "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestBackoff(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	backoff := Backoff{Delay: time.Second, MaxDelay: 10 * time.Second}
+
+	delays := []time.Duration{}
+	for attempt := 0; attempt < 6; attempt++ {
+		delays = append(delays, backoff.delay(attempt, 0))
+	}
+	g.Expect(delays).To(o.Equal([]time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}))
+	g.Expect(backoff.delay(1000, 0)).To(o.Equal(10*time.Second), "checking the delay cap of many attempts")
+
+	for i := 0; i < 100; i++ {
+		g.Expect(backoff.delay(1, 0.5)).To(o.And(
+			o.BeNumerically(">=", 2*time.Second),
+			o.BeNumerically("<=", 3*time.Second),
+		), "checking the jittered delay")
+		g.Expect(backoff.delay(3, 0.5)).To(o.BeNumerically("<=", 10*time.Second), "checking the jittered delay cap")
+	}
+}
+
+func TestRequeuePolicy(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	policy := NewRequeuePolicy(map[RequeueReason]Backoff{
+		RequeueWaiting: {Delay: 10 * time.Second, MaxDelay: time.Minute},
+	}, 0)
+	g.Expect(policy.Backoffs[RequeueConfigurationError]).To(o.Equal(DefaultBackoffs()[RequeueConfigurationError]))
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	other := reconcile.Request{NamespacedName: types.NamespacedName{Name: "other"}}
+
+	delay, attempts := policy.Next(req, RequeueWaiting)
+	g.Expect(delay).To(o.Equal(10 * time.Second))
+	g.Expect(attempts).To(o.Equal(1))
+	delay, attempts = policy.Next(req, RequeueWaiting)
+	g.Expect(delay).To(o.Equal(20 * time.Second))
+	g.Expect(attempts).To(o.Equal(2))
+	delay, _ = policy.Next(req, RequeueConfigurationError)
+	g.Expect(delay).To(o.Equal(2*time.Minute), "checking the backoff of the configuration errors")
+	g.Expect(policy.NumRequeues(req)).To(o.Equal(3))
+
+	// the work queue uses the delay recorded by the policy
+	g.Expect(policy.When(req)).To(o.Equal(2 * time.Minute))
+	g.Expect(policy.When(other)).To(o.Equal(time.Second), "checking the transient error backoff is used by default")
+	g.Expect(policy.NumRequeues(other)).To(o.Equal(1))
+
+	policy.Forget(req)
+	g.Expect(policy.NumRequeues(req)).To(o.BeZero())
+	g.Expect(policy.attempts).NotTo(o.HaveKey(req), "checking that forgetting clears the attempts")
+	g.Expect(policy.delays).NotTo(o.HaveKey(req), "checking that forgetting clears the delays")
+	delay, attempts = policy.Next(req, RequeueWaiting)
+	g.Expect(delay).To(o.Equal(10 * time.Second))
+	g.Expect(attempts).To(o.Equal(1))
+
+	// the attempts forgotten by the work queue are restored from the status
+	policy.Forget(req)
+	policy.Restore(req, 2)
+	delay, attempts = policy.Next(req, RequeueWaiting)
+	g.Expect(delay).To(o.Equal(40 * time.Second))
+	g.Expect(attempts).To(o.Equal(3))
+}
@@ -1,14 +1,67 @@
 package controllers
 
 import (
+	"errors"
 	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// Requeue triggers a object requeue.
+// RequeueReason classifies why an object is reconciled again, selecting the backoff of the
+// requeue in the RequeuePolicy.
+type RequeueReason string
+
+const (
+	// RequeueWaiting is used while waiting for a precondition, such as a CRD or a dependency like
+	// cert-manager, to be installed.
+	RequeueWaiting RequeueReason = "Waiting"
+	// RequeueTransientError is used for errors which may go away on their own, such as API errors.
+	RequeueTransientError RequeueReason = "TransientError"
+	// RequeueConfigurationError is used for errors which need the configuration to be fixed.
+	RequeueConfigurationError RequeueReason = "ConfigurationError"
+)
+
+// requeueError carries the reason of a requeue, and its cause when it is caused by an error, back
+// to Reconcile.
+type requeueError struct {
+	reason RequeueReason
+	err    error
+}
+
+func (e *requeueError) Error() string {
+	if e.err == nil {
+		return string(e.reason)
+	}
+	return e.err.Error()
+}
+
+func (e *requeueError) Unwrap() error {
+	return e.err
+}
+
+// requeueReason returns the reason and the cause of the requeue caused by the given error. Errors
+// without a reason are transient.
+func requeueReason(err error) (RequeueReason, error) {
+	var reqErr *requeueError
+	if errors.As(err, &reqErr) {
+		return reqErr.reason, reqErr.err
+	}
+	return RequeueTransientError, err
+}
+
+// Requeue triggers a object requeue, backed off while waiting for a precondition.
 func Requeue() (ctrl.Result, error) {
-	return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	return RequeueFor(RequeueWaiting, nil)
+}
+
+// RequeueFor triggers a object requeue for the informed reason, and error when not nil.
+func RequeueFor(reason RequeueReason, err error) (ctrl.Result, error) {
+	return ctrl.Result{}, &requeueError{reason: reason, err: err}
+}
+
+// RequeueAfter triggers a object requeue after the informed duration.
+func RequeueAfter(d time.Duration) (ctrl.Result, error) {
+	return ctrl.Result{RequeueAfter: d}, nil
 }
 
 // RequeueOnError triggers requeue when error is not nil.
@@ -16,9 +69,10 @@ func RequeueOnError(err error) (ctrl.Result, error) {
 	return ctrl.Result{}, err
 }
 
-// RequeueWithError triggers a object requeue because the informed error happend.
+// RequeueWithError triggers a object requeue because the informed error happend, backed off as a
+// transient error.
 func RequeueWithError(err error) (ctrl.Result, error) {
-	return ctrl.Result{RequeueAfter: 1 * time.Second}, err
+	return RequeueFor(RequeueTransientError, err)
 }
 
 // NoRequeue all done, the object does not need reconciliation anymore.
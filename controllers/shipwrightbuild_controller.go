@@ -8,29 +8,49 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/manifestival/manifestival"
+	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/apis/operator/v1alpha1"
 	tektonoperatorv1alpha1client "github.com/tektoncd/operator/pkg/client/clientset/versioned/typed/operator/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/buildstrategy"
+	"github.com/shipwright-io/operator/pkg/certificates"
 	"github.com/shipwright-io/operator/pkg/certmanager"
 	"github.com/shipwright-io/operator/pkg/common"
+	"github.com/shipwright-io/operator/pkg/platform"
 	"github.com/shipwright-io/operator/pkg/tekton"
 	"github.com/shipwright-io/operator/pkg/triggers"
 )
@@ -43,6 +63,31 @@ const (
 
 	// Ready object is providing service.
 	ConditionReady = "Ready"
+	// ConditionTektonReady reports whether Tekton Pipelines is ready.
+	ConditionTektonReady = "TektonReady"
+	// ConditionCertificatesReady reports whether the webhook certificates are ready.
+	ConditionCertificatesReady = "CertificatesReady"
+	// ConditionBuildControllerReady reports whether the Shipwright Build controller is ready.
+	ConditionBuildControllerReady = "BuildControllerReady"
+	// ConditionWebhookReady reports whether the Shipwright Build conversion webhook is ready.
+	ConditionWebhookReady = "WebhookReady"
+	// ConditionBuildStrategiesReady reports whether the cluster build strategies are installed.
+	ConditionBuildStrategiesReady = "BuildStrategiesReady"
+	// ConditionTriggersReady reports whether Shipwright Triggers is ready, or not deployed.
+	ConditionTriggersReady = "TriggersReady"
+	// ConditionDegraded reports components which failed to roll out within the rollout timeout.
+	ConditionDegraded = "Degraded"
+	// ConditionBuildStrategiesModified reports cluster build strategies modified outside of the
+	// operator.
+	ConditionBuildStrategiesModified = "BuildStrategiesModified"
+	// ConditionTektonCompatible reports whether the installed Tekton Pipelines version and feature
+	// flags are supported by the deployed Shipwright Build release.
+	ConditionTektonCompatible = "TektonCompatible"
+	// ConditionOverridesApplied reports whether the overrides of the rendered resources are valid
+	// and applied.
+	ConditionOverridesApplied = "OverridesApplied"
+	// ConditionDryRun reports that the changes are planned on the status instead of applied.
+	ConditionDryRun = "DryRun"
 
 	// UseManagedWebhookCerts is an env Var that controls wether we install the webhook certs
 	UseManagedWebhookCerts = "USE_MANAGED_WEBHOOK_CERTS"
@@ -50,6 +95,19 @@ const (
 	CertManagerInjectAnnotationKey = "cert-manager.io/inject-ca-from"
 
 	CertManagerInjectAnnotationValueTemplate = "%s/shipwright-build-webhook-cert"
+
+	// ShipwrightBuildLabel is set on the rendered resources to map them back to the owning
+	// ShipwrightBuild.
+	ShipwrightBuildLabel = "operator.shipwright.io/shipwrightbuild"
+	// DryRunAnnotation set to "true" on a ShipwrightBuild makes the reconciler report the changes
+	// it would make to the cluster on the status, instead of applying them.
+	DryRunAnnotation = "operator.shipwright.io/dry-run"
+
+	// defaultRolloutTimeout is the time given to the component Deployments to roll out, before
+	// reporting them as degraded.
+	defaultRolloutTimeout = 5 * time.Minute
+	// rolloutRequeueInterval is the interval at which an ongoing rollout is checked.
+	rolloutRequeueInterval = 10 * time.Second
 )
 
 // ShipwrightBuildReconciler reconciles a ShipwrightBuild object
@@ -57,6 +115,11 @@ type ShipwrightBuildReconciler struct {
 	client.Client        // controller kubernetes client
 	CRDClient            crdclientv1.ApiextensionsV1Interface
 	TektonOperatorClient tektonoperatorv1alpha1client.OperatorV1alpha1Interface
+	APIReader            client.Reader      // uncached reader, used to look up pods
+	RolloutTimeout       time.Duration      // time given to component Deployments to roll out
+	ResyncInterval       time.Duration      // periodic reconciliation interval, disabled when zero
+	RequeuePolicy        *RequeuePolicy     // backoff of failing or waiting reconciliations
+	Platform             *platform.Platform // platform and capabilities detected at startup
 
 	Logger                logr.Logger           // decorated logger
 	Scheme                *runtime.Scheme       // runtime scheme
@@ -64,6 +127,13 @@ type ShipwrightBuildReconciler struct {
 	TektonManifest        manifestival.Manifest // Tekton release manifest render
 	BuildStrategyManifest manifestival.Manifest // Build strategies manifest to render
 	TriggersManifest      manifestival.Manifest // Triggers manifest to render
+	TektonSupportMatrix   tekton.SupportMatrix  // Tekton Pipelines versions supported by Build
+
+	controller             controller.Controller // controller instance, to register watches lazily
+	cache                  cache.Cache           // manager cache backing the lazy watches
+	watchMutex             sync.Mutex            // guards the lazy watches registration
+	buildStrategiesWatched bool                  // the ClusterBuildStrategy watch is registered
+	tektonConfigsWatched   bool                  // the TektonConfig watch is registered
 }
 
 type TektonCheckResult struct {
@@ -78,7 +148,13 @@ func (r *ShipwrightBuildReconciler) setFinalizer(ctx context.Context, b *v1alpha
 		return nil
 	}
 	b.SetFinalizers(append(b.GetFinalizers(), FinalizerAnnotation))
-	return r.Update(ctx, b, &client.UpdateOptions{})
+	// the update response carries the stored status, keep the one being reconciled instead
+	status := b.Status.DeepCopy()
+	if err := r.Update(ctx, b, &client.UpdateOptions{}); err != nil {
+		return err
+	}
+	b.Status = *status
+	return nil
 }
 
 // unsetFinalizer remove all instances of local finalizer string, updating the resource immediately.
@@ -95,28 +171,40 @@ func (r *ShipwrightBuildReconciler) unsetFinalizer(ctx context.Context, b *v1alp
 	return r.Update(ctx, b, &client.UpdateOptions{})
 }
 
-// deleteObjectsIfPresent deletes all the given objects if they are present in the cluster.
+// setCondition sets the given condition on the ShipwrightBuild status, stamped with the object's
+// generation.
+func setCondition(b *v1alpha1.ShipwrightBuild, condition metav1.Condition) {
+	condition.ObservedGeneration = b.Generation
+	apimeta.SetStatusCondition(&b.Status.Conditions, condition)
+}
+
+// setComponentNotReady sets the condition of a component which is not ready, and reports it on
+// the Ready condition as well.
+func setComponentNotReady(b *v1alpha1.ShipwrightBuild, condition metav1.Condition) {
+	setCondition(b, condition)
+	condition.Type = ConditionReady
+	setCondition(b, condition)
+}
+
+// deleteObjectsIfPresent deletes all the given objects if they are present in the cluster. The
+// objects are deleted without being read first, as the cache only holds the labeled ones.
 func deleteObjectsIfPresent(ctx context.Context, k8sClient client.Client, objs []client.Object) error {
 	for _, obj := range objs {
-		err := k8sClient.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, obj)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				return nil
-			}
-			return fmt.Errorf("getting object %s: %v", obj.GetName(), err)
-		}
-		err = k8sClient.Delete(ctx, obj)
-		if err != nil {
+		if err := k8sClient.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
 			return fmt.Errorf("deleting object %s: %v", obj.GetName(), err)
 		}
 	}
 	return nil
 }
 
-// fetchAndCheckTektonConfig fetches the "config" `TektonConfig` instance on the cluster, and checks if its "Ready" condition reports `True`.
+// fetchAndCheckTektonConfig fetches the `TektonConfig` instance on the cluster, preferably named
+// "config", and checks if its "Ready" condition reports `True`. When it is not ready, the condition
+// to set describes since when it has not been ready, and which of its components are not ready.
+// The message only changes with the TektonConfig, so that reconciliations do not update the status
+// while waiting.
 // Returns `TektonCheckResult` which contains the result of the check and the condition to set.
 func (r *ShipwrightBuildReconciler) fetchAndCheckTektonConfig(ctx context.Context, logger logr.Logger) TektonCheckResult {
-	tektonConfig, err := r.TektonOperatorClient.TektonConfigs().Get(ctx, "config", metav1.GetOptions{})
+	tektonConfig, err := tekton.GetTektonConfig(ctx, r.TektonOperatorClient)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Error(err, "TektonConfig not found")
@@ -124,7 +212,7 @@ func (r *ShipwrightBuildReconciler) fetchAndCheckTektonConfig(ctx context.Contex
 				IsReady: false,
 				Err:     err,
 				ConditionToSet: &metav1.Condition{
-					Type:    ConditionReady,
+					Type:    ConditionTektonReady,
 					Status:  metav1.ConditionFalse,
 					Reason:  "TektonConfigMissing",
 					Message: "TektonConfig is missing from the cluster",
@@ -137,7 +225,7 @@ func (r *ShipwrightBuildReconciler) fetchAndCheckTektonConfig(ctx context.Contex
 			IsReady: false,
 			Err:     err,
 			ConditionToSet: &metav1.Condition{
-				Type:    ConditionReady,
+				Type:    ConditionTektonReady,
 				Status:  metav1.ConditionFalse,
 				Reason:  "TektonConfigFetchError",
 				Message: fmt.Sprintf("Unexpected error fetching TektonConfig: %v", err),
@@ -145,84 +233,424 @@ func (r *ShipwrightBuildReconciler) fetchAndCheckTektonConfig(ctx context.Contex
 		}
 	}
 
-	for _, condition := range tektonConfig.Status.Conditions {
-		if condition.Type == ConditionReady && condition.Status == corev1.ConditionTrue {
-			logger.Info("TektonConfig is ready")
+	if tekton.IsTektonConfigReady(tektonConfig) {
+		logger.Info("TektonConfig is ready", "tektonConfig", tektonConfig.Name)
+		return TektonCheckResult{
+			IsReady: true,
+			Err:     nil,
+			ConditionToSet: &metav1.Condition{
+				Type:    ConditionTektonReady,
+				Status:  metav1.ConditionTrue,
+				Reason:  "TektonReady",
+				Message: "TektonConfig is Ready",
+			},
+		}
+	}
+
+	// the TektonConfig transition time is stable, unlike the time elapsed since then, which is left
+	// to the transition time of the condition
+	message := fmt.Sprintf("TektonConfig %s is not Ready", tektonConfig.Name)
+	if since := tekton.TektonConfigNotReadySince(tektonConfig); !since.IsZero() {
+		message = fmt.Sprintf("%s since %s", message, since.UTC().Format(time.RFC3339))
+	}
+	if components := tekton.NotReadyComponents(tektonConfig); len(components) > 0 {
+		message = fmt.Sprintf("%s; not ready: %s", message, strings.Join(components, "; "))
+	}
+
+	logger.Info("TektonConfig is not ready yet", "tektonConfig", tektonConfig.Name)
+	return TektonCheckResult{
+		IsReady: false,
+		Err:     nil,
+		ConditionToSet: &metav1.Condition{
+			Type:    ConditionTektonReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "TektonNotReady",
+			Message: message,
+		},
+	}
+}
+
+// checkTektonPipelines checks whether the Tekton Pipelines controller and webhook Deployments
+// installed from the embedded release manifest are rolled out.
+func (r *ShipwrightBuildReconciler) checkTektonPipelines(ctx context.Context, logger logr.Logger) TektonCheckResult {
+	for _, name := range []string{tekton.PipelinesControllerDeployment, tekton.PipelinesWebhookDeployment} {
+		d := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: tekton.PipelinesNamespace, Name: name}, d)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to fetch Tekton Pipelines deployment", "deployment", name)
 			return TektonCheckResult{
-				IsReady: true,
+				IsReady: false,
+				Err:     err,
+				ConditionToSet: &metav1.Condition{
+					Type:    ConditionTektonReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  "TektonPipelinesFetchError",
+					Message: fmt.Sprintf("Unexpected error fetching deployment %s: %v", name, err),
+				},
+			}
+		}
+		complete, message := false, "deployment is not found"
+		if err == nil {
+			complete, message = common.DeploymentRolloutStatus(d)
+		}
+		if !complete {
+			logger.Info("Tekton Pipelines is not ready yet", "deployment", name)
+			return TektonCheckResult{
+				IsReady: false,
 				Err:     nil,
 				ConditionToSet: &metav1.Condition{
-					Type:    ConditionReady,
-					Status:  metav1.ConditionTrue,
-					Reason:  "TektonReady",
-					Message: "TektonConfig is Ready",
+					Type:    ConditionTektonReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  "TektonNotReady",
+					Message: fmt.Sprintf("Tekton Pipelines deployment %s is not available: %s", name, message),
 				},
 			}
 		}
 	}
 
-	logger.Info("TektonConfig is not ready yet")
+	logger.Info("Tekton Pipelines is ready")
 	return TektonCheckResult{
-		IsReady: false,
+		IsReady: true,
 		Err:     nil,
 		ConditionToSet: &metav1.Condition{
-			Type:    ConditionReady,
-			Status:  metav1.ConditionFalse,
-			Reason:  "TektonNotReady",
-			Message: "TektonConfig is not Ready",
+			Type:    ConditionTektonReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "TektonReady",
+			Message: "Tekton Pipelines is Ready",
 		},
 	}
 }
 
-// Reconcile performs the resource reconciliation steps to deploy or remove Shipwright Build
-// instances. When deletion-timestamp is found, the removal of the previously deploy resources is
-// executed, otherwise the regular deploy workflow takes place.
-func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := r.Logger.WithValues("namespace", req.Namespace, "name", req.Name)
-	logger.Info("Starting resource reconciliation...")
-	// ReconcileTekton
-	_, requeue, err := tekton.ReconcileTekton(ctx, r.CRDClient, r.TektonOperatorClient)
+// checkTektonCompatibility reports on the ShipwrightBuild conditions whether the installed Tekton
+// Pipelines version and feature flags are supported by the given Shipwright Build version.
+func (r *ShipwrightBuildReconciler) checkTektonCompatibility(ctx context.Context, b *v1alpha1.ShipwrightBuild, buildVersion string) error {
+	namespace, err := r.tektonPipelinesNamespace(ctx, b)
 	if err != nil {
-		requeueInterval := 0 * time.Second
-		if requeue {
-			requeueInterval = 1 * time.Second
+		return err
+	}
+	pipelinesInfo, err := r.tektonConfigMapData(ctx, namespace, tekton.PipelinesInfoConfigMap)
+	if err != nil {
+		return err
+	}
+	featureFlags, err := r.tektonConfigMapData(ctx, namespace, tekton.FeatureFlagsConfigMap)
+	if err != nil {
+		return err
+	}
+	status, reason, message := r.TektonSupportMatrix.Check(buildVersion, pipelinesInfo["version"], featureFlags)
+	setCondition(b, metav1.Condition{
+		Type:    ConditionTektonCompatible,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	return nil
+}
+
+// tektonPipelinesNamespace returns the namespace where Tekton Pipelines is deployed, either by
+// the operator or by the Tekton Operator.
+func (r *ShipwrightBuildReconciler) tektonPipelinesNamespace(ctx context.Context, b *v1alpha1.ShipwrightBuild) (string, error) {
+	if b.Spec.TektonPipelinesInstalled() {
+		return tekton.PipelinesNamespace, nil
+	}
+	tektonConfig, err := tekton.GetTektonConfig(ctx, r.TektonOperatorClient)
+	if errors.IsNotFound(err) {
+		return tekton.PipelinesNamespace, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if tektonConfig.Spec.TargetNamespace == "" {
+		return tekton.PipelinesNamespace, nil
+	}
+	return tektonConfig.Spec.TargetNamespace, nil
+}
+
+// tektonConfigMapData returns the data of the named Tekton ConfigMap, or nil when it is not found.
+// ConfigMaps are read without the cache, to avoid caching every ConfigMap of the cluster.
+func (r *ShipwrightBuildReconciler) tektonConfigMapData(ctx context.Context, namespace, name string) (map[string]string, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return configMap.Data, nil
+}
+
+// platform returns the platform detected at startup, or the platform set by the PLATFORM
+// environment variable when it was not detected.
+func (r *ShipwrightBuildReconciler) platform() *platform.Platform {
+	if r.Platform == nil {
+		return platform.FromEnv()
+	}
+	return r.Platform
+}
+
+// uncachedReader returns the reader used for the objects which are not watched by the operator.
+func (r *ShipwrightBuildReconciler) uncachedReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// proxy returns the egress proxy of the ShipwrightBuild, completed with the cluster-wide Proxy
+// when the platform provides one.
+func (r *ShipwrightBuildReconciler) proxy(ctx context.Context, b *v1alpha1.ShipwrightBuild) (*v1alpha1.ProxySpec, error) {
+	if !r.platform().Capabilities.Has(platform.ClusterProxy) {
+		return b.Spec.Proxy, nil
+	}
+	clusterProxy, err := platform.GetClusterProxy(ctx, r.uncachedReader())
+	if err != nil {
+		return nil, err
+	}
+	return common.MergeProxy(clusterProxy, b.Spec.Proxy), nil
+}
+
+// trustedCA returns the trusted CA ConfigMap synced to the target namespace, with the hash of its
+// bundle. On OpenShift, when no ConfigMap is referenced, the ConfigMap is labeled for the cluster
+// bundle to be injected, and the hash is the one of the injected bundle, empty until it is
+// injected. It returns nil when no trusted CA is configured.
+func (r *ShipwrightBuildReconciler) trustedCA(ctx context.Context, b *v1alpha1.ShipwrightBuild, targetNamespace string) (*unstructured.Unstructured, string, error) {
+	if b.Spec.TrustedCA == nil {
+		return nil, "", nil
+	}
+	ref := b.Spec.TrustedCA.ConfigMap
+	if ref == nil {
+		if !r.platform().OpenShift {
+			return nil, "", fmt.Errorf("trustedCA requires a ConfigMap on %s", r.platform().Name())
+		}
+		configMap := common.TrustedCAConfigMapObject("", true)
+		injected := &corev1.ConfigMap{}
+		err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: targetNamespace, Name: common.TrustedCAConfigMap}, injected)
+		if errors.IsNotFound(err) {
+			return &configMap, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("reading trusted CA ConfigMap %s/%s: %w", targetNamespace, common.TrustedCAConfigMap, err)
 		}
-		return ctrl.Result{RequeueAfter: requeueInterval}, err
+		bundle, exists := injected.Data[common.TrustedCAKey]
+		if !exists {
+			return &configMap, "", nil
+		}
+		return &configMap, common.TrustedCAHash(bundle), nil
 	}
-	if requeue {
-		return Requeue()
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = targetNamespace
+	}
+	key := ref.Key
+	if key == "" {
+		key = common.TrustedCAKey
 	}
+	source := &corev1.ConfigMap{}
+	if err := r.uncachedReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, source); err != nil {
+		return nil, "", fmt.Errorf("reading trusted CA ConfigMap %s/%s: %w", namespace, ref.Name, err)
+	}
+	bundle, exists := source.Data[key]
+	if !exists {
+		return nil, "", fmt.Errorf("trusted CA ConfigMap %s/%s has no %q key", namespace, ref.Name, key)
+	}
+	configMap := common.TrustedCAConfigMapObject(bundle, false)
+	return &configMap, common.TrustedCAHash(bundle), nil
+}
+
+// reconcileTektonPipelines installs or upgrades Tekton Pipelines from the embedded release
+// manifest.
+func (r *ShipwrightBuildReconciler) reconcileTektonPipelines(ctx context.Context, b *v1alpha1.ShipwrightBuild, proxy *v1alpha1.ProxySpec) error {
+	manifest, err := r.renderTektonPipelines(b, proxy)
+	if err != nil {
+		return err
+	}
+	return tekton.ReconcileTektonPipelines(ctx, r.CRDClient, manifest, ShipwrightBuildLabel)
+}
+
+// Reconcile reconciles the ShipwrightBuild, and backs off its requeue by the requeue policy when
+// the reconciliation fails or waits for a precondition. The status of the reconciliation, with
+// the next retry, is updated once it is done.
+func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger.WithValues("namespace", req.Namespace, "name", req.Name)
+	policy := r.requeuePolicy()
 
 	// retrieving the ShipwrightBuild instance requested for reconcile
 	b := &v1alpha1.ShipwrightBuild{}
 	if err := r.Get(ctx, req.NamespacedName, b); err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("Resource is not found!")
+			policy.Forget(req)
 			return NoRequeue()
 		}
 		logger.Error(err, "retrieving ShipwrightBuild object from cache")
 		return RequeueOnError(err)
 	}
+	status := b.Status.DeepCopy()
+	if status.Retry != nil {
+		policy.Restore(req, int(status.Retry.Attempts))
+	}
+
+	result, err := r.reconcile(ctx, logger, b)
+	var delay time.Duration
+	var cause error
+	if err == nil {
+		policy.Forget(req)
+		b.Status.Retry = nil
+	} else {
+		var reason RequeueReason
+		var attempts int
+		reason, cause = requeueReason(err)
+		delay, attempts = policy.Next(req, reason)
+		b.Status.Retry = &v1alpha1.RetryStatus{
+			Reason:        string(reason),
+			Attempts:      int32(attempts),
+			NextRetryTime: metav1.NewTime(time.Now().Add(delay).Truncate(time.Second)),
+		}
+	}
+	if !equality.Semantic.DeepEqual(status, &b.Status) {
+		// the ShipwrightBuild is gone once the finalizer is removed from it
+		if updateErr := r.Client.Status().Update(ctx, b); client.IgnoreNotFound(updateErr) != nil {
+			logger.Error(updateErr, "updating ShipwrightBuild status")
+			if err == nil {
+				return RequeueOnError(updateErr)
+			}
+		}
+	}
+
+	switch {
+	case err == nil:
+		return result, nil
+	case cause == nil:
+		return RequeueAfter(delay)
+	}
+	// the work queue delays the requeue by the delay recorded by the policy
+	return RequeueOnError(cause)
+}
+
+// requeuePolicy returns the requeue policy, instantiating the default one when not informed.
+func (r *ShipwrightBuildReconciler) requeuePolicy() *RequeuePolicy {
+	if r.RequeuePolicy == nil {
+		r.RequeuePolicy = NewRequeuePolicy(nil, 0)
+	}
+	return r.RequeuePolicy
+}
+
+// reconcile performs the resource reconciliation steps to deploy or remove Shipwright Build
+// instances. When deletion-timestamp is found, the removal of the previously deploy resources is
+// executed, otherwise the regular deploy workflow takes place. The status is set on the given
+// ShipwrightBuild, and updated by Reconcile.
+func (r *ShipwrightBuildReconciler) reconcile(ctx context.Context, logger logr.Logger, b *v1alpha1.ShipwrightBuild) (ctrl.Result, error) {
+	logger.Info("Starting resource reconciliation...")
+	b.Status.ObservedGeneration = b.Generation
+	b.Status.ManagementState = b.Spec.EffectiveManagementState()
+	b.Status.Platform = &v1alpha1.PlatformStatus{
+		Name:         r.platform().Name(),
+		Capabilities: r.platform().Capabilities.List(),
+	}
 	init := b.Status.Conditions == nil
 	if init {
 		b.Status.Conditions = make([]metav1.Condition, 0)
-		apimeta.SetStatusCondition(&b.Status.Conditions, metav1.Condition{
+		setCondition(b, metav1.Condition{
 			Type:    ConditionReady,
 			Status:  metav1.ConditionUnknown, // we just started trying to reconcile
 			Reason:  "Init",
 			Message: "Initializing Shipwright Operator",
 		})
-		if err := r.Client.Status().Update(ctx, b); err != nil {
+	}
+
+	// the changes are left out in the Unmanaged state, planned on the status instead of applied in
+	// dry-run mode, and the components are uninstalled in the Removed state, unless the
+	// ShipwrightBuild is being deleted
+	state := b.Spec.EffectiveManagementState()
+	planning := dryRun(b) && state != v1alpha1.ManagementStateUnmanaged
+	if !planning {
+		b.Status.Plan = nil
+		apimeta.RemoveStatusCondition(&b.Status.Conditions, ConditionDryRun)
+	}
+
+	// when deletion-timestamp is set, the reconciliation process is in fact deleting the resources
+	// previously deployed. To mark the deletion process as done, it needs to clean up the
+	// finalizers, and thus the ShipwrightBuild is removed from cache. The prerequisites of the
+	// installation, such as Tekton, the cluster proxy or the trusted CA, are not checked, so that
+	// they cannot block the deletion
+	if !b.GetDeletionTimestamp().IsZero() {
+		logger.Info("DeletionTimestamp is set...")
+		if !common.Contains(b.GetFinalizers(), FinalizerAnnotation) {
+			logger.Info("Finalizers removed, deletion of manifests completed!")
+			return NoRequeue()
+		}
+		logger.Info("Deleting components...")
+		if err := r.uninstall(ctx, b, effectiveTargetNamespace(b)); err != nil {
+			logger.Error(err, "deleting the components")
+			return RequeueWithError(err)
+		}
+		logger.Info("Removing finalizers...")
+		if err := r.unsetFinalizer(ctx, b); err != nil {
+			logger.Error(err, "removing the finalizer")
+			return RequeueWithError(err)
+		}
+		logger.Info("All removed!")
+		return NoRequeue()
+	}
+
+	switch {
+	case state == v1alpha1.ManagementStateUnmanaged:
+		return r.reconcileUnmanaged(logger)
+	case planning:
+		return r.reconcilePlan(ctx, logger, b)
+	case state == v1alpha1.ManagementStateRemoved:
+		return r.reconcileRemoved(ctx, logger, b)
+	}
+
+	proxy, err := r.proxy(ctx, b)
+	if err != nil {
+		logger.Error(err, "reading the cluster proxy")
+		return RequeueWithError(err)
+	}
+
+	// ReconcileTekton
+	var requeue bool
+	if b.Spec.TektonPipelinesInstalled() {
+		err = r.reconcileTektonPipelines(ctx, b, proxy)
+	} else {
+		_, requeue, err = tekton.ReconcileTekton(ctx, r.CRDClient, r.TektonOperatorClient, b.Spec.Tekton)
+	}
+	if err != nil {
+		logger.Error(err, "reconciling Tekton Pipelines")
+		setComponentNotReady(b, metav1.Condition{
+			Type:    ConditionTektonReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Reconciling Tekton Pipelines failed: %v", err),
+		})
+		// errors without requeue wait for Tekton to be installed or configured
+		if requeue {
+			return RequeueWithError(err)
+		}
+		return RequeueFor(RequeueWaiting, err)
+	}
+	if requeue {
+		return Requeue()
+	}
+	tektonConfigsWatched := false
+	if !b.Spec.TektonPipelinesInstalled() {
+		if tektonConfigsWatched, err = r.watchTektonConfigs(ctx); err != nil {
+			logger.Error(err, "watching TektonConfigs")
 			return RequeueWithError(err)
 		}
 	}
 
 	// Check TektonConfig status, update status and requeue if not ready
-	tektonconfigCheck := r.fetchAndCheckTektonConfig(ctx, logger)
-	apimeta.SetStatusCondition(&b.Status.Conditions, *tektonconfigCheck.ConditionToSet)
-	if updateErr := r.Client.Status().Update(ctx, b); updateErr != nil {
-		logger.Error(updateErr, "Failed to update ShipwrightBuild status")
-		return RequeueOnError(updateErr)
+	var tektonconfigCheck TektonCheckResult
+	if b.Spec.TektonPipelinesInstalled() {
+		tektonconfigCheck = r.checkTektonPipelines(ctx, logger)
+	} else {
+		tektonconfigCheck = r.fetchAndCheckTektonConfig(ctx, logger)
+	}
+	if tektonconfigCheck.IsReady {
+		setCondition(b, *tektonconfigCheck.ConditionToSet)
+	} else {
+		setComponentNotReady(b, *tektonconfigCheck.ConditionToSet)
 	}
 
 	if tektonconfigCheck.Err != nil {
@@ -231,6 +659,11 @@ func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	if !tektonconfigCheck.IsReady {
+		// TektonConfig readiness changes trigger a new reconciliation once they are watched
+		if tektonConfigsWatched {
+			logger.Info("TektonConfig is not ready, waiting for it to change")
+			return NoRequeue()
+		}
 		logger.Info("TektonConfig is not ready, requeueing request")
 		return Requeue()
 	}
@@ -264,80 +697,142 @@ func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		logger.Info("created target namespace")
 	}
 
-	// ReconcileCertManager
-	if common.BoolFromEnvVar(UseManagedWebhookCerts) {
-		requeue, err = certmanager.ReconcileCertManager(ctx, r.CRDClient, r.Client, r.Logger, targetNamespace)
+	// Reconcile the webhook certificates
+	settings := r.newRenderSettings(b, targetNamespace)
+	settings.proxy = proxy
+	switch settings.certificatesMode {
+	case v1alpha1.CertificatesModeCertManager:
+		requeue, err = certmanager.ReconcileCertManager(ctx, r.CRDClient, r.Client, r.Logger, targetNamespace, b.Spec.Certificates)
 		if err != nil {
-			requeueInterval := 0 * time.Second
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Failed",
+				Message: fmt.Sprintf("Reconciling webhook certificates failed: %v", err),
+			})
+			// errors without requeue wait for cert-manager to be installed
 			if requeue {
-				requeueInterval = 1 * time.Second
+				return RequeueWithError(err)
 			}
-			return ctrl.Result{RequeueAfter: requeueInterval}, err
+			return RequeueFor(RequeueWaiting, err)
 		}
 		if requeue {
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "CertificatesWaiting",
+				Message: "Waiting for cert-manager to be installed",
+			})
 			return Requeue()
 		}
-	}
-
-	// Applying transformers
-	// image transformers: Alow to inject custom component images
-	// namespace transformer: Allow installing in a specific namespace
-	// InjetAnnotation transformer for webhook certs management via cert manager
-	images := common.ToLowerCaseKeys(common.ImagesFromEnv(common.ShipwrightImagePrefix))
-
-	transformerfncs := []manifestival.Transformer{}
-	transformerfncs = append(transformerfncs, common.TruncateCRDFieldTransformer("description", 50))
-	if common.IsOpenShiftPlatform() {
-		transformerfncs = append(transformerfncs, manifestival.InjectNamespace(targetNamespace))
-		transformerfncs = append(transformerfncs, common.DeploymentImages(images))
-	} else {
-		transformerfncs = append(transformerfncs, manifestival.InjectNamespace(targetNamespace))
-		transformerfncs = append(transformerfncs, common.DeploymentImages(images))
-		transformerfncs = append(transformerfncs, common.InjectAnnotations(CertManagerInjectAnnotationKey, fmt.Sprintf(CertManagerInjectAnnotationValueTemplate, targetNamespace), common.Overwrite, "CustomResourceDefinition"))
-	}
-
-	manifest, err := r.Manifest.
-		Filter(manifestival.Not(manifestival.ByKind("Namespace"))).
-		Transform(transformerfncs...)
-
-	if err != nil {
-		logger.Error(err, "transforming manifests, injecting namespace")
-		return RequeueWithError(err)
-	}
-
-	// when deletion-timestamp is set, the reconciliation process is in fact deleting the resources
-	// previously deployed. To mark the deletion process as done, it needs to clean up the
-	// finalizers, and thus the ShipwrightBuild is removed from cache
-	if !b.GetDeletionTimestamp().IsZero() {
-		logger.Info("DeletionTimestamp is set...")
-		if !common.Contains(b.GetFinalizers(), FinalizerAnnotation) {
-			logger.Info("Finalizers removed, deletion of manifests completed!")
-			return NoRequeue()
-		}
-		logger.Info("Deleting triggers resources")
-		if err := r.deleteTriggersManifest(targetNamespace); err != nil {
-			logger.Error(err, "deleting triggers resources")
+		certificate, err := certmanager.GetCertificateStatus(ctx, r.uncachedReader(), targetNamespace)
+		if err != nil {
+			logger.Error(err, "reading the webhook certificate status")
 			return RequeueWithError(err)
 		}
-
-		logger.Info("Deleting cluster build strategies")
-		if err := r.BuildStrategyManifest.Delete(); err != nil {
-			logger.Error(err, "deleting cluster build strategies")
-			return RequeueWithError(err)
+		b.Status.Certificates = &v1alpha1.CertificatesStatus{
+			Issuer:      certmanager.IssuerName(b.Spec.Certificates),
+			Ready:       certificate.Ready,
+			NotAfter:    certificate.NotAfter,
+			RenewalTime: certificate.RenewalTime,
 		}
-
-		logger.Info("Deleting manifests...")
-		if err := manifest.Filter(manifestival.NoCRDs).Delete(); err != nil {
-			logger.Error(err, "deleting manifest's resources")
+		if !certificate.Ready {
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CertificateNotReady",
+				Message: fmt.Sprintf("Webhook certificate issued by %s is not ready: %s", b.Status.Certificates.Issuer, certificate.Message),
+			})
+			return Requeue()
+		}
+		setCondition(b, metav1.Condition{
+			Type:    ConditionCertificatesReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Reconciled",
+			Message: fmt.Sprintf("Webhook certificates are managed with cert-manager, issued by %s%s", b.Status.Certificates.Issuer, validUntil(certificate.NotAfter)),
+		})
+	case v1alpha1.CertificatesModeBuiltIn:
+		dnsNames := certificates.WebhookDNSNames(targetNamespace)
+		if b.Spec.Certificates != nil {
+			dnsNames = append(dnsNames, b.Spec.Certificates.DNSNames...)
+		}
+		settings.builtInCertificates, err = certificates.ReconcileBuiltIn(ctx, r.uncachedReader(), r.Client, targetNamespace,
+			dnsNames, map[string]string{ShipwrightBuildLabel: b.Name}, time.Now())
+		if err != nil {
+			logger.Error(err, "reconciling built-in webhook certificates")
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Failed",
+				Message: fmt.Sprintf("Reconciling webhook certificates failed: %v", err),
+			})
 			return RequeueWithError(err)
 		}
-		logger.Info("Removing finalizers...")
-		if err := r.unsetFinalizer(ctx, b); err != nil {
-			logger.Error(err, "removing the finalizer")
+		b.Status.Certificates = &v1alpha1.CertificatesStatus{
+			Issuer:      string(v1alpha1.CertificatesModeBuiltIn),
+			Ready:       true,
+			NotAfter:    &metav1.Time{Time: settings.builtInCertificates.NotAfter},
+			RenewalTime: &metav1.Time{Time: settings.builtInCertificates.RenewAt},
+		}
+		setCondition(b, metav1.Condition{
+			Type:    ConditionCertificatesReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Reconciled",
+			Message: "Webhook certificates are generated by the operator" + validUntil(b.Status.Certificates.NotAfter),
+		})
+	case v1alpha1.CertificatesModeServiceCA:
+		serviceCA, err := certificates.GetServiceCA(ctx, r.uncachedReader(), targetNamespace)
+		if err != nil {
+			logger.Error(err, "reading the webhook certificates issued by the service CA")
 			return RequeueWithError(err)
 		}
-		logger.Info("All removed!")
-		return NoRequeue()
+		// the certificate is issued once the webhook Service is applied, the webhook rollout
+		// waits for it meanwhile
+		b.Status.Certificates = &v1alpha1.CertificatesStatus{
+			Issuer:   string(v1alpha1.CertificatesModeServiceCA),
+			Ready:    serviceCA.Ready,
+			NotAfter: serviceCA.NotAfter,
+		}
+		if serviceCA.Ready {
+			setCondition(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Reconciled",
+				Message: "Webhook certificates are managed by the OpenShift service CA" + validUntil(serviceCA.NotAfter),
+			})
+		} else {
+			setCondition(b, metav1.Condition{
+				Type:    ConditionCertificatesReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Issuing",
+				Message: "Waiting for the OpenShift service CA to issue the webhook certificates",
+			})
+		}
+	default:
+		b.Status.Certificates = nil
+		setCondition(b, metav1.Condition{
+			Type:    ConditionCertificatesReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Unmanaged",
+			Message: "Webhook certificates are not managed by the operator",
+		})
+	}
+
+	settings.trustedCAConfigMap, settings.trustedCAHash, err = r.trustedCA(ctx, b, targetNamespace)
+	if err != nil {
+		logger.Error(err, "reading the trusted CA bundle")
+		return RequeueFor(RequeueConfigurationError, err)
+	}
+
+	manifest, err := r.renderBuild(b, settings)
+	if err != nil {
+		logger.Error(err, "transforming manifests, injecting namespace")
+		return RequeueFor(RequeueConfigurationError, err)
+	}
+	effectiveImages, err := common.EffectiveImages(manifest.Resources())
+	if err != nil {
+		logger.Error(err, "collecting effective images")
+		return RequeueWithError(err)
 	}
 
 	// rolling out the resources described on the manifests, it should create a new Shipwright Build
@@ -345,16 +840,22 @@ func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	logger.Info("Applying manifest's resources...")
 	if err := manifest.Apply(); err != nil {
 		logger.Error(err, "rolling out manifest's resources")
-		apimeta.SetStatusCondition(&b.Status.Conditions, metav1.Condition{
+		for _, conditionType := range []string{ConditionBuildControllerReady, ConditionWebhookReady} {
+			setCondition(b, metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Failed",
+				Message: fmt.Sprintf("Applying Shipwright Build manifests failed: %v", err),
+			})
+		}
+		setCondition(b, metav1.Condition{
 			Type:    ConditionReady,
 			Status:  metav1.ConditionFalse,
 			Reason:  "Failed",
 			Message: fmt.Sprintf("Reconciling ShipwrightBuild failed: %v", err),
 		})
-		err = r.Client.Status().Update(ctx, b)
 		return RequeueWithError(err)
 	}
-
 	// Builds 0.12.0 created a ClusterRole and ClusterRolebinding for the Build API conversion webhook.
 	// These were removed in v0.13.0 - when upgrading, these should be removed if present.
 	err = deleteObjectsIfPresent(ctx, r.Client, []client.Object{
@@ -366,99 +867,534 @@ func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return RequeueWithError(err)
 	}
 
+	// the trusted CA ConfigMap is part of the manifest while configured, and removed afterwards
+	if settings.trustedCAConfigMap == nil {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: common.TrustedCAConfigMap}}
+		if err := r.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "deleting the trusted CA ConfigMap")
+			return RequeueWithError(err)
+		}
+	}
+
 	if err := r.setFinalizer(ctx, b); err != nil {
 		logger.Info(fmt.Sprintf("%#v", b))
 		logger.Error(err, "setting the finalizer")
 		return RequeueWithError(err)
 	}
 
-	requeue, err = buildstrategy.ReconcileBuildStrategies(ctx,
+	sourceStrategies, sourceStatuses := buildstrategy.LoadSources(ctx,
+		r.uncachedReader(),
+		b.Spec.BuildStrategies,
+		targetNamespace,
+		buildstrategy.BuildStrategyNames(r.BuildStrategyManifest))
+	for _, status := range sourceStatuses {
+		if status.Error != "" {
+			logger.Info("skipping cluster build strategy source", "kind", status.Kind, "namespace", status.Namespace, "name", status.Name, "error", status.Error)
+		}
+	}
+	b.Status.BuildStrategySources = sourceStatuses
+	sourceManifest, err := manifestival.ManifestFrom(manifestival.Slice(sourceStrategies),
+		manifestival.UseClient(r.BuildStrategyManifest.Client),
+		manifestival.UseLogger(logger))
+	if err != nil {
+		logger.Error(err, "loading cluster build strategy sources")
+		return RequeueWithError(err)
+	}
+
+	buildStrategyManifest, err := r.renderBuildStrategies(b, settings, sourceManifest)
+	if err != nil {
+		logger.Error(err, "transforming cluster build strategies manifests")
+		return RequeueFor(RequeueConfigurationError, err)
+	}
+	installedStrategies, _ := buildstrategy.SelectBuildStrategies(buildStrategyManifest, b.Spec.BuildStrategies)
+	strategyImages, err := common.EffectiveImages(installedStrategies.Resources())
+	if err != nil {
+		logger.Error(err, "collecting effective cluster build strategy images")
+		return RequeueWithError(err)
+	}
+	effectiveImages = common.MergeImages(effectiveImages, strategyImages)
+
+	requeue, modifiedStrategies, err := buildstrategy.ReconcileBuildStrategies(ctx,
 		r.CRDClient,
 		logger,
-		r.BuildStrategyManifest)
+		buildStrategyManifest,
+		b.Spec.BuildStrategies)
 	if err != nil {
 		logger.Error(err, "reconcile cluster build strategies")
+		setComponentNotReady(b, metav1.Condition{
+			Type:    ConditionBuildStrategiesReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Reconciling cluster build strategies failed: %v", err),
+		})
 		return RequeueWithError(err)
 	}
 	if requeue {
 		logger.Info("requeue waiting for cluster build strategy preconditions")
-		apimeta.SetStatusCondition(&b.Status.Conditions, metav1.Condition{
-			Type:    ConditionReady,
+		setComponentNotReady(b, metav1.Condition{
+			Type:    ConditionBuildStrategiesReady,
 			Status:  metav1.ConditionUnknown,
 			Reason:  "ClusterBuildStrategiesWaiting",
 			Message: "Waiting for cluster build strategies to be deployed",
 		})
-		if updateErr := r.Client.Status().Update(ctx, b); updateErr != nil {
-			return RequeueWithError(err)
-		}
 		return Requeue()
 	}
+	if err := r.watchBuildStrategies(); err != nil {
+		logger.Error(err, "watching cluster build strategies")
+		return RequeueWithError(err)
+	}
+	if err := buildstrategy.PruneSourceStrategies(ctx, r.uncachedReader(), r.Client, buildStrategyManifest, sourceStatuses, b.Spec.BuildStrategies); err != nil {
+		logger.Error(err, "pruning cluster build strategies from sources")
+		return RequeueWithError(err)
+	}
+	setCondition(b, metav1.Condition{
+		Type:    ConditionBuildStrategiesReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Installed",
+		Message: fmt.Sprintf("%d cluster build strategies are installed", len(buildstrategy.BuildStrategyNames(installedStrategies))),
+	})
+	if len(modifiedStrategies) > 0 {
+		setCondition(b, metav1.Condition{
+			Type:    ConditionBuildStrategiesModified,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ModifiedOnCluster",
+			Message: fmt.Sprintf("Cluster build strategies were modified on the cluster: %s", strings.Join(modifiedStrategies, ", ")),
+		})
+	} else {
+		setCondition(b, metav1.Condition{
+			Type:    ConditionBuildStrategiesModified,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InSync",
+			Message: "Cluster build strategies match the operator's manifests",
+		})
+	}
+
+	versions := v1alpha1.ComponentVersions{
+		Build: common.DeploymentVersion(manifest.Resources(), common.BuildControllerDeployment),
+	}
+	appliedResources := append(manifest.Resources(), installedStrategies.Resources()...)
+	if err := r.checkTektonCompatibility(ctx, b, versions.Build); err != nil {
+		logger.Error(err, "checking Tekton Pipelines compatibility")
+		return RequeueWithError(err)
+	}
+	rollouts := map[string]string{
+		ConditionBuildControllerReady: common.BuildControllerDeployment,
+		ConditionWebhookReady:         common.BuildWebhookDeployment,
+	}
 
 	// Reconcile triggers
 	if b.Spec.TriggersEnabled() {
-		triggersManifest, err := r.TriggersManifest.
-			Filter(manifestival.Not(manifestival.ByKind("Namespace"))).
-			Transform(
-				// TODO: Remove this when we remove the target namespace feature.
-				// See https://github.com/shipwright-io/operator/issues/241
-				manifestival.InjectNamespace(targetNamespace),
-				common.DeploymentImages(images),
-			)
+		triggersManifest, err := r.renderTriggers(b, settings)
 		if err != nil {
 			logger.Error(err, "transforming triggers manifests")
+			return RequeueFor(RequeueConfigurationError, err)
+		}
+		triggersImages, err := common.EffectiveImages(triggersManifest.Resources())
+		if err != nil {
+			logger.Error(err, "collecting effective triggers images")
 			return RequeueWithError(err)
 		}
+		effectiveImages = common.MergeImages(effectiveImages, triggersImages)
 
 		requeue, err = triggers.ReconcileTriggers(ctx, r.CRDClient, logger, triggersManifest)
 		if err != nil {
 			logger.Error(err, "reconcile triggers")
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionTriggersReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Failed",
+				Message: fmt.Sprintf("Reconciling triggers failed: %v", err),
+			})
 			return RequeueWithError(err)
 		}
 		if requeue {
 			logger.Info("requeue waiting for triggers preconditions")
-			apimeta.SetStatusCondition(&b.Status.Conditions, metav1.Condition{
-				Type:    ConditionReady,
+			setComponentNotReady(b, metav1.Condition{
+				Type:    ConditionTriggersReady,
 				Status:  metav1.ConditionUnknown,
 				Reason:  "TriggersWaiting",
 				Message: "Waiting for triggers preconditions to be met",
 			})
-			if updateErr := r.Client.Status().Update(ctx, b); updateErr != nil {
-				return RequeueWithError(updateErr)
-			}
 			return Requeue()
 		}
+		rollouts[ConditionTriggersReady] = common.TriggersDeployment
+		versions.Triggers = common.DeploymentVersion(triggersManifest.Resources(), common.TriggersDeployment)
+		appliedResources = append(appliedResources, triggersManifest.Resources()...)
 	} else {
 		if err := r.deleteTriggersManifest(targetNamespace); err != nil {
 			logger.Error(err, "cleaning up triggers resources")
 			return RequeueWithError(err)
 		}
+		setCondition(b, metav1.Condition{
+			Type:    ConditionTriggersReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Disabled",
+			Message: "Triggers are not deployed",
+		})
 	}
 
-	apimeta.SetStatusCondition(&b.Status.Conditions, metav1.Condition{
+	setOverridesCondition(b, settings.overrides)
+	b.Status.Images = effectiveImages
+	b.Status.BuildStrategies = buildstrategy.BuildStrategyNames(installedStrategies)
+	b.Status.Versions = versions
+	b.Status.AppliedResources = common.ResourceReferences(appliedResources)
+
+	// the installation is only ready once the component Deployments are rolled out
+	available, err := r.checkRollouts(ctx, b, targetNamespace, rollouts)
+	if err != nil {
+		logger.Error(err, "checking deployments rollout")
+		return RequeueWithError(err)
+	}
+	if !available {
+		logger.Info("requeue waiting for deployments rollout")
+		return RequeueAfter(rolloutRequeueInterval)
+	}
+	setCondition(b, metav1.Condition{
 		Type:    ConditionReady,
 		Status:  metav1.ConditionTrue,
 		Reason:  "Success",
 		Message: "Reconciled ShipwrightBuild successfully",
 	})
-	if err := r.Client.Status().Update(ctx, b); err != nil {
-		logger.Error(err, "updating ShipwrightBuild status")
+	logger.Info("All done!")
+	requeueAfter := r.ResyncInterval
+	if b.Status.Certificates != nil && b.Status.Certificates.RenewalTime != nil {
+		// the built-in certificates are rotated, and the status of the certificates issued by
+		// cert-manager is refreshed, by the reconciliation following their renewal time
+		untilRenewal := max(time.Until(b.Status.Certificates.RenewalTime.Time), time.Second)
+		if requeueAfter == 0 || untilRenewal < requeueAfter {
+			requeueAfter = untilRenewal
+		}
+	}
+	if requeueAfter > 0 {
+		return RequeueAfter(requeueAfter)
+	}
+	return NoRequeue()
+}
+
+// reconcilePlan reports the changes the reconciliation would make on the status, without applying
+// them. The plan is refreshed with the periodic reconciliation.
+func (r *ShipwrightBuildReconciler) reconcilePlan(ctx context.Context, logger logr.Logger, b *v1alpha1.ShipwrightBuild) (ctrl.Result, error) {
+	logger.Info("Dry-run is set, planning the changes...")
+	plan, err := r.plan(ctx, b)
+	if err != nil {
+		logger.Error(err, "planning the changes")
+		setCondition(b, metav1.Condition{
+			Type:    ConditionDryRun,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Planning the changes failed: %v", err),
+		})
 		return RequeueWithError(err)
 	}
-	logger.Info("All done!")
+	b.Status.Plan = plan
+	setCondition(b, metav1.Condition{
+		Type:    ConditionDryRun,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Planned",
+		Message: planMessage(plan),
+	})
+	if r.ResyncInterval > 0 {
+		return RequeueAfter(r.ResyncInterval)
+	}
+	return NoRequeue()
+}
+
+// reconcileUnmanaged leaves the components as they are on the cluster, while the management state
+// is Unmanaged. The conditions report the state of the last managed reconciliation, only the
+// management state is updated on the status.
+func (r *ShipwrightBuildReconciler) reconcileUnmanaged(logger logr.Logger) (ctrl.Result, error) {
+	logger.Info("Management state is Unmanaged, the components are left as they are")
+	return NoRequeue()
+}
+
+// reconcileRemoved uninstalls the components while the management state is Removed, keeping the
+// ShipwrightBuild.
+func (r *ShipwrightBuildReconciler) reconcileRemoved(ctx context.Context, logger logr.Logger, b *v1alpha1.ShipwrightBuild) (ctrl.Result, error) {
+	logger.Info("Management state is Removed, uninstalling the components...")
+	if err := r.uninstall(ctx, b, effectiveTargetNamespace(b)); err != nil {
+		logger.Error(err, "uninstalling the components")
+		setCondition(b, metav1.Condition{
+			Type:    ConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Uninstalling the components failed: %v", err),
+		})
+		return RequeueWithError(err)
+	}
+	for _, conditionType := range []string{
+		ConditionCertificatesReady,
+		ConditionBuildControllerReady,
+		ConditionWebhookReady,
+		ConditionBuildStrategiesReady,
+		ConditionTriggersReady,
+		ConditionDegraded,
+		ConditionBuildStrategiesModified,
+		ConditionTektonCompatible,
+		ConditionOverridesApplied,
+	} {
+		apimeta.RemoveStatusCondition(&b.Status.Conditions, conditionType)
+	}
+	setCondition(b, metav1.Condition{
+		Type:    ConditionReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Removed",
+		Message: "The components are uninstalled, as the management state is Removed",
+	})
+	b.Status.Versions = v1alpha1.ComponentVersions{}
+	b.Status.AppliedResources = nil
+	b.Status.Images = nil
+	b.Status.BuildStrategies = nil
+	b.Status.BuildStrategySources = nil
+	b.Status.Certificates = nil
 	return NoRequeue()
 }
 
+// setOverridesCondition reports the overrides which are invalid or failed to apply. The condition
+// is removed when there are no overrides.
+func setOverridesCondition(b *v1alpha1.ShipwrightBuild, overrides *common.Overrides) {
+	if len(b.Spec.Overrides) == 0 {
+		apimeta.RemoveStatusCondition(&b.Status.Conditions, ConditionOverridesApplied)
+		return
+	}
+	if errs := overrides.Errors(); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		setCondition(b, metav1.Condition{
+			Type:    ConditionOverridesApplied,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidOverrides",
+			Message: fmt.Sprintf("Overrides were skipped: %s", strings.Join(messages, "; ")),
+		})
+		return
+	}
+	setCondition(b, metav1.Condition{
+		Type:    ConditionOverridesApplied,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Applied",
+		Message: fmt.Sprintf("%d overrides are applied", len(b.Spec.Overrides)),
+	})
+}
+
+// validUntil describes the expiry of a certificate, when it is known.
+func validUntil(notAfter *metav1.Time) string {
+	if notAfter == nil {
+		return ""
+	}
+	return fmt.Sprintf(", valid until %s", notAfter.UTC().Format(time.RFC3339))
+}
+
+// effectiveTargetNamespace returns the namespace the components are deployed to, the default one
+// when not informed.
+func effectiveTargetNamespace(b *v1alpha1.ShipwrightBuild) string {
+	if b.Spec.TargetNamespace == "" {
+		return defaultTargetNamespace
+	}
+	return b.Spec.TargetNamespace
+}
+
+// certificatesMode returns how the webhook certificates are managed, as set by
+// spec.certificates.mode, or by the USE_MANAGED_WEBHOOK_CERTS environment variable and the
+// capabilities of the platform otherwise.
+func (r *ShipwrightBuildReconciler) certificatesMode(b *v1alpha1.ShipwrightBuild) v1alpha1.CertificatesMode {
+	if b.Spec.Certificates != nil && b.Spec.Certificates.Mode != "" {
+		return b.Spec.Certificates.Mode
+	}
+	if common.BoolFromEnvVar(UseManagedWebhookCerts) {
+		return v1alpha1.CertificatesModeCertManager
+	}
+	if r.platform().Capabilities.Has(platform.ServiceCA) {
+		return v1alpha1.CertificatesModeServiceCA
+	}
+	return v1alpha1.CertificatesModeUnmanaged
+}
+
+// checkRollouts reports the rollout state of the given component Deployments, keyed by component
+// condition type, on the ShipwrightBuild conditions. Components which are not rolled out within
+// the rollout timeout are reported as failed, along with the Degraded condition. Returns true when
+// all Deployments are available.
+func (r *ShipwrightBuildReconciler) checkRollouts(ctx context.Context, b *v1alpha1.ShipwrightBuild, namespace string, rollouts map[string]string) (bool, error) {
+	timeout := r.RolloutTimeout
+	if timeout == 0 {
+		timeout = defaultRolloutTimeout
+	}
+
+	conditionTypes := []string{}
+	for conditionType := range rollouts {
+		conditionTypes = append(conditionTypes, conditionType)
+	}
+	slices.Sort(conditionTypes)
+
+	available := true
+	degraded := metav1.Condition{
+		Type:    ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: "All deployments are rolled out",
+	}
+	for _, conditionType := range conditionTypes {
+		name := rollouts[conditionType]
+		d := &appsv1.Deployment{}
+		complete, message := false, fmt.Sprintf("deployment %s is not found", name)
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, d)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if err == nil {
+			complete, message = common.DeploymentRolloutStatus(d)
+		}
+		if complete {
+			setCondition(b, metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Available",
+				Message: fmt.Sprintf("Deployment %s is available", name),
+			})
+			continue
+		}
+
+		available = false
+		previous := apimeta.FindStatusCondition(b.Status.Conditions, conditionType)
+		timedOut := previous != nil && previous.Reason == "RolloutTimeout"
+		if previous != nil && previous.Reason == "RollingOut" {
+			timedOut = time.Since(previous.LastTransitionTime.Time) >= timeout
+		}
+		if !timedOut {
+			setComponentNotReady(b, metav1.Condition{
+				Type:    conditionType,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "RollingOut",
+				Message: fmt.Sprintf("Waiting for deployment %s rollout: %s", name, message),
+			})
+			continue
+		}
+
+		message = fmt.Sprintf("Deployment %s did not roll out within %s: %s", name, timeout, message)
+		reason := "RolloutTimeout"
+		if err == nil {
+			podReason, podMessage, err := r.podFailure(ctx, d)
+			if err != nil {
+				return false, err
+			}
+			if podReason != "" {
+				reason = podReason
+				message = fmt.Sprintf("%s, %s", message, podMessage)
+			}
+		}
+		setComponentNotReady(b, metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RolloutTimeout",
+			Message: message,
+		})
+		if degraded.Status != metav1.ConditionTrue {
+			degraded = metav1.Condition{
+				Type:    ConditionDegraded,
+				Status:  metav1.ConditionTrue,
+				Reason:  reason,
+				Message: message,
+			}
+		}
+	}
+	if available || degraded.Status == metav1.ConditionTrue ||
+		!apimeta.IsStatusConditionTrue(b.Status.Conditions, ConditionDegraded) {
+		setCondition(b, degraded)
+	}
+	return available, nil
+}
+
+// podFailure looks up the pods of the given Deployment, and returns the reason and message
+// explaining why one of them is not running.
+func (r *ShipwrightBuildReconciler) podFailure(ctx context.Context, d *appsv1.Deployment) (string, string, error) {
+	if d.Spec.Selector == nil {
+		return "", "", nil
+	}
+	pods := &corev1.PodList{}
+	err := r.uncachedReader().List(ctx, pods, client.InNamespace(d.Namespace), client.MatchingLabels(d.Spec.Selector.MatchLabels))
+	if err != nil {
+		return "", "", err
+	}
+	reason, message := common.PodFailure(pods.Items)
+	return reason, message, nil
+}
+
 // deleteTriggersManifest deletes the triggers resources in the given namespace.
 func (r *ShipwrightBuildReconciler) deleteTriggersManifest(targetNamespace string) error {
-	triggersManifest, err := r.TriggersManifest.
+	triggersManifest, err := namespacedManifest(r.TriggersManifest, targetNamespace)
+	if err != nil {
+		return err
+	}
+	return triggersManifest.Delete()
+}
+
+// namespacedManifest returns the resources of the manifest in the given namespace, without the
+// Namespace itself.
+func namespacedManifest(manifest manifestival.Manifest, targetNamespace string) (manifestival.Manifest, error) {
+	return manifest.
 		Filter(manifestival.Not(manifestival.ByKind("Namespace"))).
 		// TODO: Remove this when we remove the target namespace feature.
 		// See https://github.com/shipwright-io/operator/issues/241
 		Transform(manifestival.InjectNamespace(targetNamespace))
+}
+
+// uninstallManifests returns the manifests of the resources removed when the components are
+// uninstalled, in the order of their removal: the triggers resources, the build strategies
+// managed by the operator, including the ones loaded from sources, and the Shipwright Build
+// resources with the trusted CA ConfigMap and the built-in webhook certificates. The CRDs are
+// kept, as they hold the resources of the users.
+func (r *ShipwrightBuildReconciler) uninstallManifests(ctx context.Context, b *v1alpha1.ShipwrightBuild, targetNamespace string) ([]manifestival.Manifest, error) {
+	triggersManifest, err := namespacedManifest(r.TriggersManifest, targetNamespace)
+	if err != nil {
+		return nil, err
+	}
+	sourceStrategies, err := buildstrategy.PrunedSourceStrategies(ctx, r.uncachedReader(), manifestival.Manifest{}, nil, b.Spec.BuildStrategies)
+	if err != nil {
+		return nil, err
+	}
+	sourceManifest, err := manifestival.ManifestFrom(manifestival.Slice(sourceStrategies),
+		manifestival.UseClient(r.BuildStrategyManifest.Client))
+	if err != nil {
+		return nil, err
+	}
+	buildManifest, err := namespacedManifest(r.Manifest.Filter(manifestival.NoCRDs), targetNamespace)
+	if err != nil {
+		return nil, err
+	}
+	objects := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: common.TrustedCAConfigMap}},
+	}
+	if r.certificatesMode(b) == v1alpha1.CertificatesModeBuiltIn {
+		objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: targetNamespace, Name: certificates.WebhookSecret}})
+	}
+	objectsManifest, err := r.objectsManifest(objects...)
+	if err != nil {
+		return nil, err
+	}
+	buildStrategyManifest, err := buildstrategy.InstalledBuildStrategies(r.BuildStrategyManifest, b.Spec.BuildStrategies)
+	if err != nil {
+		return nil, err
+	}
+	return []manifestival.Manifest{
+		triggersManifest,
+		buildStrategyManifest,
+		sourceManifest,
+		buildManifest.Append(objectsManifest),
+	}, nil
+}
+
+// uninstall removes the resources of the components from the cluster.
+func (r *ShipwrightBuildReconciler) uninstall(ctx context.Context, b *v1alpha1.ShipwrightBuild, targetNamespace string) error {
+	manifests, err := r.uninstallManifests(ctx, b, targetNamespace)
 	if err != nil {
 		return err
 	}
-	return triggersManifest.Delete()
+	for _, manifest := range manifests {
+		if err := manifest.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // setupManifestival instantiate manifestival with local controller attributes, as well as tekton prereqs.
@@ -476,16 +1412,29 @@ func (r *ShipwrightBuildReconciler) setupManifestival() error {
 	if err != nil {
 		return err
 	}
+	r.TektonManifest, err = common.SetupManifestival(r.Client, "tekton-pipelines-release.yaml", false, r.Logger)
+	if err != nil {
+		return err
+	}
+	dataPath, err := common.KoDataPath()
+	if err != nil {
+		return err
+	}
+	r.TektonSupportMatrix, err = tekton.LoadSupportMatrix(filepath.Join(dataPath, "tekton-compatibility.yaml"))
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager, by instantiating Manifestival and
-// setting up watch and predicate rules for ShipwrightBuild objects.
+// setting up watch and predicate rules for ShipwrightBuild objects, and for the resources rendered
+// from the release manifests, so that changes made to them are reverted.
 func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := r.setupManifestival(); err != nil {
 		return err
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	bldr := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.ShipwrightBuild{}, builder.WithPredicates(predicate.Funcs{
 			CreateFunc: func(ce event.CreateEvent) bool {
 				// all new objects must be subject to reconciliation
@@ -496,9 +1445,172 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return !e.DeleteStateUnknown
 			},
 			UpdateFunc: func(e event.UpdateEvent) bool {
-				// objects that have updated generation must be subject to reconciliation
-				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+				// objects that have updated generation, or that are switched in or out of the
+				// dry-run mode, must be subject to reconciliation
+				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+					e.ObjectOld.GetAnnotations()[DryRunAnnotation] != e.ObjectNew.GetAnnotations()[DryRunAnnotation]
 			},
-		})).
-		Complete(r)
+		}))
+	// rendered resources are mapped back to their ShipwrightBuild, Deployment rollouts are
+	// reflected on its status and modified or deleted resources are restored
+	for _, obj := range ownedObjects() {
+		bldr = bldr.Watches(obj,
+			handler.EnqueueRequestsFromMapFunc(shipwrightBuildForObject),
+			builder.WithPredicates(ownedPredicate()))
+	}
+	// CRDs are not labeled when they are installed by other means, their metadata is watched
+	// instead of restricting the cache by label, which also keeps their schemas out of the cache
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	bldr = bldr.Watches(crd,
+		handler.EnqueueRequestsFromMapFunc(shipwrightBuildForObject),
+		builder.WithPredicates(ownedPredicate()))
+	// the cluster-wide proxy is propagated to the components, which are updated when it changes
+	if r.platform().Capabilities.Has(platform.ClusterProxy) {
+		proxy := &metav1.PartialObjectMetadata{}
+		proxy.SetGroupVersionKind(platform.ProxyGroupVersionKind)
+		bldr = bldr.Watches(proxy, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, _ client.Object) []reconcile.Request {
+				return r.shipwrightBuildRequests(ctx)
+			}))
+	}
+	c, err := bldr.WithOptions(controller.Options{RateLimiter: r.requeuePolicy()}).Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.cache = mgr.GetCache()
+	return nil
+}
+
+// ownedObjects returns the types of the resources rendered from the release manifests which are
+// watched from the start. CRDs are watched with their metadata only, and ClusterBuildStrategies
+// are watched once their CRD is installed, see watchBuildStrategies.
+func ownedObjects() []client.Object {
+	return []client.Object{
+		&appsv1.Deployment{},
+		&corev1.Service{},
+		&corev1.ServiceAccount{},
+		&rbacv1.ClusterRole{},
+		&rbacv1.ClusterRoleBinding{},
+		&rbacv1.Role{},
+		&rbacv1.RoleBinding{},
+	}
+}
+
+// CacheOptions restricts the cache of the manager to the owned objects carrying the
+// ShipwrightBuild label, so that watching them does not cache every object of their kinds in the
+// cluster. Reading other objects of these kinds requires an uncached reader.
+func CacheOptions() cache.Options {
+	owned, _ := labels.NewRequirement(ShipwrightBuildLabel, selection.Exists, nil)
+	selector := labels.NewSelector().Add(*owned)
+	byObject := map[client.Object]cache.ByObject{}
+	for _, obj := range ownedObjects() {
+		byObject[obj] = cache.ByObject{Label: selector}
+	}
+	return cache.Options{ByObject: byObject}
+}
+
+// ownedPredicate selects the objects carrying the ShipwrightBuild label.
+func ownedPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, exists := obj.GetLabels()[ShipwrightBuildLabel]
+		return exists
+	})
+}
+
+// watchBuildStrategies registers the ClusterBuildStrategy watch. The ClusterBuildStrategy CRD is
+// installed by the operator itself, so the watch can only start once it is reconciled. It does
+// nothing when the controller is not set up, or when the watch is already registered.
+func (r *ShipwrightBuildReconciler) watchBuildStrategies() error {
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+
+	if r.controller == nil || r.buildStrategiesWatched {
+		return nil
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "shipwright.io",
+		Version: "v1beta1",
+		Kind:    "ClusterBuildStrategy",
+	})
+	err := r.controller.Watch(source.Kind(r.cache, client.Object(obj),
+		handler.EnqueueRequestsFromMapFunc(shipwrightBuildForObject),
+		ownedPredicate()))
+	if err != nil {
+		return err
+	}
+	r.buildStrategiesWatched = true
+	return nil
+}
+
+// watchTektonConfigs registers the TektonConfig watch, through an informer of the Tekton Operator
+// client, which enqueues every ShipwrightBuild when the readiness of a TektonConfig changes. The
+// watch can only start once the Tekton Operator is installed. It returns whether the watch is
+// registered, which is never the case when the controller is not set up.
+func (r *ShipwrightBuildReconciler) watchTektonConfigs(ctx context.Context) (bool, error) {
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+
+	if r.controller == nil || r.tektonConfigsWatched {
+		return r.tektonConfigsWatched, nil
+	}
+	installed, err := tekton.IsTektonOperatorInstalled(ctx, r.CRDClient)
+	if err != nil || !installed {
+		return false, err
+	}
+	err = r.controller.Watch(source.Func(func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		enqueue := func() {
+			for _, req := range r.shipwrightBuildRequests(ctx) {
+				queue.Add(req)
+			}
+		}
+		informer := tekton.NewTektonConfigInformer(r.TektonOperatorClient, 0)
+		_, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(any) { enqueue() },
+			UpdateFunc: func(oldObj, newObj any) {
+				oldConfig, oldOk := oldObj.(*tektonoperatorv1alpha1.TektonConfig)
+				newConfig, newOk := newObj.(*tektonoperatorv1alpha1.TektonConfig)
+				if !oldOk || !newOk || tekton.TektonConfigReadinessChanged(oldConfig, newConfig) {
+					enqueue()
+				}
+			},
+			DeleteFunc: func(any) { enqueue() },
+		})
+		if err != nil {
+			return err
+		}
+		go informer.Run(ctx.Done())
+		return nil
+	}))
+	if err != nil {
+		return false, err
+	}
+	r.tektonConfigsWatched = true
+	return true, nil
+}
+
+// shipwrightBuildRequests returns a request for every ShipwrightBuild.
+func (r *ShipwrightBuildReconciler) shipwrightBuildRequests(ctx context.Context) []reconcile.Request {
+	list := &v1alpha1.ShipwrightBuildList{}
+	if err := r.List(ctx, list); err != nil {
+		r.Logger.Error(err, "listing ShipwrightBuilds")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, b := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&b)})
+	}
+	return requests
+}
+
+// shipwrightBuildForObject maps an object rendered by the operator back to the ShipwrightBuild
+// named by its label.
+func shipwrightBuildForObject(_ context.Context, obj client.Object) []reconcile.Request {
+	name, exists := obj.GetLabels()[ShipwrightBuildLabel]
+	if !exists || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
 }
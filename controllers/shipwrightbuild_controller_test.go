@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shipwright-io/operator/pkg/certificates"
 	"github.com/shipwright-io/operator/pkg/common"
+	"github.com/shipwright-io/operator/pkg/platform"
+	"github.com/shipwright-io/operator/pkg/tekton"
 
 	o "github.com/onsi/gomega"
 
@@ -19,7 +22,10 @@ import (
 	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
@@ -45,6 +51,7 @@ func bootstrapShipwrightBuildReconciler(
 
 	s := runtime.NewScheme()
 	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Namespace{})
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Pod{}, &corev1.PodList{}, &corev1.ConfigMap{}, &corev1.Secret{})
 	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.Deployment{})
 	s.AddKnownTypes(v1alpha1.GroupVersion, &v1alpha1.ShipwrightBuild{})
 	s.AddKnownTypes(rbacv1.SchemeGroupVersion, &rbacv1.ClusterRoleBinding{})
@@ -295,6 +302,11 @@ func TestShipwrightBuildReconciler_OperandReadiness(t *testing.T) {
 						Reason:  "Installed",
 						Message: "TektonConfig is not ready",
 					},
+					{
+						Type:    tektonoperatorv1alpha1.ComponentsReady,
+						Status:  corev1.ConditionFalse,
+						Message: "TektonPipeline: reconcile again and proceed",
+					},
 				},
 			},
 		},
@@ -351,11 +363,28 @@ func TestShipwrightBuildReconciler_OperandReadiness(t *testing.T) {
 	err = c.Get(ctx, req.NamespacedName, updated)
 	g.Expect(err).To(o.BeNil())
 	g.Expect(updated.Status.IsReady()).To(o.BeFalse(), "ShipwrightBuild should not be ready when TektonConfig is not ready")
+	tektonReady := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionTektonReady)
+	g.Expect(tektonReady.Reason).To(o.Equal("TektonNotReady"))
+	g.Expect(tektonReady.Message).To(o.Equal("TektonConfig config is not Ready; not ready: ComponentsReady: TektonPipeline: reconcile again and proceed"))
+	g.Expect(updated.Status.Retry).NotTo(o.BeNil(), "ShipwrightBuild should report the next retry")
+	g.Expect(updated.Status.Retry.Reason).To(o.Equal(string(RequeueWaiting)))
+	g.Expect(updated.Status.Retry.Attempts).To(o.Equal(int32(1)))
+	g.Expect(updated.Status.Retry.NextRetryTime.Time).To(o.BeTemporally("~", time.Now().Add(res.RequeueAfter), 2*time.Second))
+
+	// the work queue forgets the requeues after a delay, the attempts are restored from the status
+	r.RequeuePolicy.Forget(req)
+	res, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	err = c.Get(ctx, req.NamespacedName, updated)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(updated.Status.Retry.Attempts).To(o.Equal(int32(2)))
+	g.Expect(res.RequeueAfter).To(o.BeNumerically(">", r.RequeuePolicy.Backoffs[RequeueWaiting].Delay))
 
 	// Simulate TektonConfig becoming ready
 	tektonConfig.Status.Conditions[0].Status = corev1.ConditionTrue
 	tektonConfig.Status.Conditions[0].Reason = "Installed"
 	tektonConfig.Status.Conditions[0].Message = "TektonConfig is now ready"
+	tektonConfig.Status.Conditions[1].Status = corev1.ConditionTrue
 	_, err = r.TektonOperatorClient.TektonConfigs().Update(ctx, tektonConfig, metav1.UpdateOptions{})
 	g.Expect(err).To(o.BeNil())
 
@@ -365,10 +394,759 @@ func TestShipwrightBuildReconciler_OperandReadiness(t *testing.T) {
 	// Trigger reconciliation again
 	res, err = r.Reconcile(ctx, req)
 	g.Expect(err).To(o.BeNil())
-	g.Expect(res.RequeueAfter).To(o.BeZero(), "Should not requeue after TektonConfig is ready")
+	g.Expect(res.RequeueAfter).NotTo(o.BeZero(), "Reconciliation should requeue until the deployments are rolled out")
+
+	err = c.Get(ctx, req.NamespacedName, updated)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(updated.Status.IsReady()).To(o.BeFalse(), "ShipwrightBuild should not be ready until the deployments are rolled out")
+	g.Expect(apimeta.FindStatusCondition(updated.Status.Conditions, ConditionBuildControllerReady).Reason).To(o.Equal("RollingOut"))
+
+	// Simulate the deployments rollout
+	markDeploymentsAvailable(t, c, "namespace", common.BuildControllerDeployment, common.BuildWebhookDeployment)
+	res, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).To(o.BeZero(), "Should not requeue after TektonConfig is ready and the deployments are rolled out")
 
 	// Fetch and verify ShipwrightBuild is now ready
 	err = c.Get(ctx, req.NamespacedName, updated)
 	g.Expect(err).To(o.BeNil())
 	g.Expect(updated.Status.IsReady()).To(o.BeTrue(), "ShipwrightBuild should be ready when TektonConfig is ready")
+	g.Expect(updated.Status.Retry).To(o.BeNil(), "ShipwrightBuild should not report a retry once reconciled")
+
+	// Verify the per-component conditions and inventory
+	for _, conditionType := range []string{
+		ConditionTektonReady,
+		ConditionCertificatesReady,
+		ConditionBuildControllerReady,
+		ConditionWebhookReady,
+		ConditionBuildStrategiesReady,
+		ConditionTriggersReady,
+	} {
+		g.Expect(apimeta.IsStatusConditionTrue(updated.Status.Conditions, conditionType)).To(o.BeTrue(), "checking %s condition", conditionType)
+	}
+	g.Expect(apimeta.FindStatusCondition(updated.Status.Conditions, ConditionTriggersReady).Reason).To(o.Equal("Disabled"))
+	g.Expect(updated.Status.ObservedGeneration).To(o.Equal(updated.Generation))
+	g.Expect(updated.Status.Versions.Build).To(o.Equal("v0.20.0"))
+	g.Expect(updated.Status.Versions.Triggers).To(o.BeEmpty())
+	g.Expect(updated.Status.AppliedResources).To(o.ContainElement(v1alpha1.ResourceReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "namespace",
+		Name:       common.BuildControllerDeployment,
+	}))
+
+	// Verify the rendered resources are labeled with the owning ShipwrightBuild
+	clusterRole := &rbacv1.ClusterRole{}
+	err = c.Get(ctx, types.NamespacedName{Name: "shipwright-build-controller"}, clusterRole)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(clusterRole.Labels).To(o.HaveKeyWithValue(ShipwrightBuildLabel, "name"))
+	g.Expect(shipwrightBuildForObject(ctx, clusterRole)).To(o.ConsistOf(reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: "name"},
+	}))
+
+	// Verify the periodic resync
+	r.ResyncInterval = 10 * time.Minute
+	res, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).To(o.Equal(10*time.Minute), "Should requeue after the resync interval")
+}
+
+// TestShipwrightBuildReconciler_RolloutTimeout checks that deployments which do not roll out in
+// time are reported as degraded, along with the reason of the failing pod.
+func TestShipwrightBuildReconciler_RolloutTimeout(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec:       v1alpha1.ShipwrightBuildSpec{TargetNamespace: "namespace"},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	r.RolloutTimeout = time.Millisecond
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+
+	// the webhook is available, while the controller pod is crash looping
+	markDeploymentsAvailable(t, c, "namespace", common.BuildWebhookDeployment)
+	err = c.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace",
+			Name:      "shipwright-build-controller-1234",
+			Labels:    map[string]string{"name": "shipwright-build"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "shipwright-build",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CrashLoopBackOff",
+					Message: "back-off 5m0s restarting failed container",
+				}},
+			}},
+		},
+	})
+	g.Expect(err).To(o.BeNil())
+	time.Sleep(2 * time.Millisecond)
+
+	res, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).NotTo(o.BeZero())
+
+	updated := &v1alpha1.ShipwrightBuild{}
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	g.Expect(updated.Status.IsReady()).To(o.BeFalse())
+	g.Expect(apimeta.IsStatusConditionTrue(updated.Status.Conditions, ConditionWebhookReady)).To(o.BeTrue())
+	controllerReady := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionBuildControllerReady)
+	g.Expect(controllerReady.Status).To(o.Equal(metav1.ConditionFalse))
+	g.Expect(controllerReady.Reason).To(o.Equal("RolloutTimeout"))
+	degraded := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionDegraded)
+	g.Expect(degraded.Status).To(o.Equal(metav1.ConditionTrue))
+	g.Expect(degraded.Reason).To(o.Equal("CrashLoopBackOff"))
+	g.Expect(degraded.Message).To(o.ContainSubstring("shipwright-build-controller-1234"))
+
+	// the degraded state is cleared once the rollout completes
+	markDeploymentsAvailable(t, c, "namespace", common.BuildControllerDeployment)
+	res, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).To(o.BeZero())
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	g.Expect(updated.Status.IsReady()).To(o.BeTrue())
+	g.Expect(apimeta.IsStatusConditionFalse(updated.Status.Conditions, ConditionDegraded)).To(o.BeTrue())
+}
+
+// markDeploymentsAvailable simulates the rollout of the given deployments.
+// TestShipwrightBuildReconciler_TektonPipelinesInstall tests installing Tekton Pipelines from the
+// embedded release manifest, on a cluster without the Tekton Operator.
+func TestShipwrightBuildReconciler_TektonPipelinesInstall(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Tekton:          &v1alpha1.TektonSpec{Install: v1alpha1.TektonInstallPipelines},
+		},
+	}
+	crd := &crdv1.CustomResourceDefinition{}
+	crd.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, toClient, r := bootstrapShipwrightBuildReconciler(t, b, nil, []*crdv1.CustomResourceDefinition{crd}, &v1alpha1.ShipwrightBuild{})
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	res, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).NotTo(o.BeZero(), "Reconciliation should requeue until Tekton Pipelines is rolled out")
+
+	// Tekton Pipelines is installed without a TektonConfig
+	for _, name := range []string{tekton.PipelinesControllerDeployment, tekton.PipelinesWebhookDeployment} {
+		d := &appsv1.Deployment{}
+		err = c.Get(ctx, types.NamespacedName{Namespace: tekton.PipelinesNamespace, Name: name}, d)
+		g.Expect(err).To(o.BeNil())
+		g.Expect(d.Labels).To(o.HaveKeyWithValue(ShipwrightBuildLabel, "name"))
+	}
+	for _, action := range toClient.Actions() {
+		g.Expect(action.Matches("create", "tektonconfigs")).To(o.BeFalse(), "No TektonConfig should be created")
+	}
+
+	updated := &v1alpha1.ShipwrightBuild{}
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	tektonReady := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionTektonReady)
+	g.Expect(tektonReady.Status).To(o.Equal(metav1.ConditionFalse))
+	g.Expect(tektonReady.Reason).To(o.Equal("TektonNotReady"))
+
+	// Tekton Pipelines readiness is derived from its deployments
+	markDeploymentsAvailable(t, c, tekton.PipelinesNamespace, tekton.PipelinesControllerDeployment, tekton.PipelinesWebhookDeployment)
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	g.Expect(apimeta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTektonReady)).To(o.BeTrue())
+
+	// the embedded Tekton Pipelines release is supported by the embedded Shipwright Build release
+	tektonCompatible := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionTektonCompatible)
+	g.Expect(tektonCompatible).NotTo(o.BeNil())
+	g.Expect(tektonCompatible.Status).To(o.Equal(metav1.ConditionTrue), tektonCompatible.Message)
+	g.Expect(tektonCompatible.Message).To(o.Equal("Tekton Pipelines v1.12.0 is supported by Shipwright Build v0.20.0"))
+}
+
+// TestShipwrightBuildReconciler_BuiltInCertificates checks that the operator generates the
+// webhook certificates, and rolls out the webhook with them, when cert-manager is not used.
+func TestShipwrightBuildReconciler_BuiltInCertificates(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Certificates:    &v1alpha1.CertificatesSpec{Mode: v1alpha1.CertificatesModeBuiltIn},
+		},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	r.ResyncInterval = 10 * time.Minute
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	markDeploymentsAvailable(t, c, "namespace", common.BuildControllerDeployment, common.BuildWebhookDeployment)
+	res, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(res.RequeueAfter).To(o.Equal(10*time.Minute), "Should requeue after the resync interval, before the renewal")
+
+	secret := &corev1.Secret{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: certificates.WebhookSecret}, secret)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(secret.Labels).To(o.HaveKeyWithValue(ShipwrightBuildLabel, "name"))
+	g.Expect(secret.Data).To(o.HaveKey(certificates.CACertKey))
+
+	webhook := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildWebhookDeployment}, webhook)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(webhook.Spec.Template.Annotations).To(o.HaveKey(certificates.CertificateHashAnnotation))
+
+	updated := &v1alpha1.ShipwrightBuild{}
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	g.Expect(updated.Status.IsReady()).To(o.BeTrue())
+	certificatesReady := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionCertificatesReady)
+	g.Expect(certificatesReady.Status).To(o.Equal(metav1.ConditionTrue))
+	g.Expect(certificatesReady.Message).To(o.HavePrefix("Webhook certificates are generated by the operator, valid until"))
+	g.Expect(updated.Status.Certificates).NotTo(o.BeNil())
+	g.Expect(updated.Status.Certificates.Issuer).To(o.Equal("BuiltIn"))
+	g.Expect(updated.Status.Certificates.Ready).To(o.BeTrue())
+	g.Expect(updated.Status.Certificates.RenewalTime.Time).To(o.BeTemporally("<", updated.Status.Certificates.NotAfter.Time))
+
+	// the certificates are removed along with the ShipwrightBuild
+	g.Expect(c.Delete(ctx, updated)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: certificates.WebhookSecret}, secret)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+}
+
+// TestShipwrightBuildReconciler_ServiceCACertificates checks that the webhook certificates are
+// issued by the OpenShift service CA when the platform provides it, and cert-manager is not
+// requested.
+func TestShipwrightBuildReconciler_ServiceCACertificates(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec:       v1alpha1.ShipwrightBuildSpec{TargetNamespace: "namespace"},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	r.Platform = &platform.Platform{
+		OpenShift:    true,
+		Capabilities: platform.Capabilities{platform.Routes: true, platform.ServiceCA: true},
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+
+	service := &unstructured.Unstructured{}
+	service.SetAPIVersion("v1")
+	service.SetKind("Service")
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: certificates.WebhookService}, service)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(service.GetAnnotations()).To(o.HaveKeyWithValue(certificates.ServingCertSecretAnnotation, certificates.WebhookSecret))
+
+	updated := &v1alpha1.ShipwrightBuild{}
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	// the serving Secret is not populated yet
+	certificatesReady := apimeta.FindStatusCondition(updated.Status.Conditions, ConditionCertificatesReady)
+	g.Expect(certificatesReady.Status).To(o.Equal(metav1.ConditionFalse))
+	g.Expect(certificatesReady.Reason).To(o.Equal("Issuing"))
+	g.Expect(updated.Status.Certificates).To(o.Equal(&v1alpha1.CertificatesStatus{Issuer: "ServiceCA"}))
+	g.Expect(updated.Status.Platform).To(o.Equal(&v1alpha1.PlatformStatus{
+		Name:         "OpenShift",
+		Capabilities: []string{"Routes", "ServiceCA"},
+	}))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: certificates.WebhookSecret},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	g.Expect(c.Create(ctx, secret)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, req.NamespacedName, updated)).To(o.Succeed())
+	certificatesReady = apimeta.FindStatusCondition(updated.Status.Conditions, ConditionCertificatesReady)
+	g.Expect(certificatesReady.Status).To(o.Equal(metav1.ConditionTrue))
+	g.Expect(certificatesReady.Message).To(o.Equal("Webhook certificates are managed by the OpenShift service CA"))
+	g.Expect(updated.Status.Certificates.Ready).To(o.BeTrue())
+}
+
+// TestShipwrightBuildReconciler_Proxy checks that the cluster-wide proxy, completed by the
+// ShipwrightBuild proxy, is propagated to the components and the build strategies.
+func TestShipwrightBuildReconciler_Proxy(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Proxy:           &v1alpha1.ProxySpec{NoProxy: ".svc,.example.com"},
+		},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	r.Platform = &platform.Platform{Capabilities: platform.Capabilities{platform.ClusterProxy: true}}
+
+	clusterProxy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": platform.ClusterProxyName},
+		"status":   map[string]interface{}{"httpsProxy": "http://proxy:3128", "noProxy": ".svc"},
+	}}
+	clusterProxy.SetGroupVersionKind(platform.ProxyGroupVersionKind)
+	g.Expect(c.Create(ctx, clusterProxy)).To(o.Succeed())
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+
+	d := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(d.Spec.Template.Spec.Containers[0].Env).To(o.ContainElements(
+		corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+		corev1.EnvVar{Name: "NO_PROXY", Value: ".svc,.example.com"},
+	))
+	g.Expect(d.Spec.Template.Spec.Containers[0].Env).NotTo(o.ContainElement(o.HaveField("Name", "HTTP_PROXY")))
+}
+
+func TestShipwrightBuildReconciler_TrustedCA(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			TrustedCA: &v1alpha1.TrustedCASpec{
+				ConfigMap: &v1alpha1.ConfigMapKeyReference{Name: "corporate-ca", Namespace: "security", Key: "ca.pem"},
+			},
+		},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+
+	// the referenced ConfigMap is missing
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.HaveOccurred())
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "security", Name: "corporate-ca"},
+		Data:       map[string]string{"ca.pem": "bundle"},
+	}
+	g.Expect(c.Create(ctx, source)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+
+	configMap := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.TrustedCAConfigMap}, configMap)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(configMap.Data).To(o.Equal(map[string]string{common.TrustedCAKey: "bundle"}))
+
+	d := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(d.Spec.Template.Annotations).To(o.HaveKeyWithValue(common.TrustedCAHashAnnotation, common.TrustedCAHash("bundle")))
+	g.Expect(d.Spec.Template.Spec.Volumes).To(o.ContainElement(o.HaveField("Name", common.TrustedCAConfigMap)))
+	g.Expect(d.Spec.Template.Spec.Containers[0].VolumeMounts).To(o.ContainElement(o.HaveField("MountPath", common.TrustedCAMountPath)))
+
+	// the copy is removed once the trusted CA is no longer configured
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	b.Spec.TrustedCA = nil
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.TrustedCAConfigMap}, configMap)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+
+	// a missing ConfigMap does not block the deletion
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	b.Spec.TrustedCA = &v1alpha1.TrustedCASpec{ConfigMap: &v1alpha1.ConfigMapKeyReference{Name: "missing"}}
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	g.Expect(c.Delete(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(errors.IsNotFound(c.Get(ctx, req.NamespacedName, b))).To(o.BeTrue())
+}
+
+// TestShipwrightBuildReconciler_InjectedTrustedCA checks that the components are restarted when
+// OpenShift injects the cluster trusted CA bundle.
+func TestShipwrightBuildReconciler_InjectedTrustedCA(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			TrustedCA:       &v1alpha1.TrustedCASpec{},
+		},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	r.Platform = &platform.Platform{
+		OpenShift:    true,
+		Capabilities: platform.Capabilities{platform.Routes: true, platform.ServiceCA: true},
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+
+	// the bundle is not injected yet
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	d := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(d.Spec.Template.Annotations).NotTo(o.HaveKey(common.TrustedCAHashAnnotation))
+
+	configMap := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.TrustedCAConfigMap}, configMap)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(configMap.Labels).To(o.HaveKeyWithValue(common.InjectTrustedCABundleLabel, "true"))
+	configMap.Data = map[string]string{common.TrustedCAKey: "injected"}
+	g.Expect(c.Update(ctx, configMap)).To(o.Succeed())
+
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(d.Spec.Template.Annotations).To(o.HaveKeyWithValue(common.TrustedCAHashAnnotation, common.TrustedCAHash("injected")))
+}
+
+func TestShipwrightBuildReconciler_Overrides(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec: v1alpha1.ShipwrightBuildSpec{
+			TargetNamespace: "namespace",
+			Overrides: []v1alpha1.ResourceOverride{{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment", Name: common.BuildControllerDeployment},
+				Type:   v1alpha1.PatchTypeStrategic,
+				Patch:  "spec:\n  template:\n    spec:\n      priorityClassName: system-cluster-critical\n",
+			}, {
+				Target: v1alpha1.OverrideTarget{Kind: "ClusterBuildStrategy"},
+				Type:   v1alpha1.PatchTypeJSON6902,
+				Patch:  "{}",
+			}},
+		},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+
+	// invalid overrides are reported without failing the reconciliation
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+
+	d := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(d.Spec.Template.Spec.PriorityClassName).To(o.Equal("system-cluster-critical"))
+
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	condition := apimeta.FindStatusCondition(b.Status.Conditions, ConditionOverridesApplied)
+	g.Expect(condition).NotTo(o.BeNil())
+	g.Expect(condition.Status).To(o.Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(o.Equal("InvalidOverrides"))
+	g.Expect(condition.Message).To(o.ContainSubstring("overrides[1]: json6902 patches must be lists of operations"))
+}
+
+func TestShipwrightBuildReconciler_DryRun(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "name",
+			Annotations: map[string]string{DryRunAnnotation: "true"},
+		},
+		Spec: v1alpha1.ShipwrightBuildSpec{TargetNamespace: "namespace"},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+
+	// the installation is planned without being applied
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	d := &appsv1.Deployment{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.GetFinalizers()).To(o.BeEmpty())
+	g.Expect(b.Status.Plan).NotTo(o.BeNil())
+	g.Expect(b.Status.Plan.Creates).To(o.BeNumerically("==", len(b.Status.Plan.Changes)))
+	g.Expect(b.Status.Plan.Changes).To(o.ContainElements(
+		o.And(o.HaveField("Action", v1alpha1.PlanActionCreate), o.HaveField("Kind", "Deployment"), o.HaveField("Name", common.BuildControllerDeployment)),
+		o.And(o.HaveField("Action", v1alpha1.PlanActionCreate), o.HaveField("Kind", "ClusterBuildStrategy"), o.HaveField("Name", "kaniko")),
+	))
+	condition := apimeta.FindStatusCondition(b.Status.Conditions, ConditionDryRun)
+	g.Expect(condition).NotTo(o.BeNil())
+	g.Expect(condition.Status).To(o.Equal(metav1.ConditionTrue))
+
+	// leaving the dry-run mode applies the changes and clears the plan
+	delete(b.Annotations, DryRunAnnotation)
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)).To(o.Succeed())
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.Plan).To(o.BeNil())
+	g.Expect(apimeta.FindStatusCondition(b.Status.Conditions, ConditionDryRun)).To(o.BeNil())
+
+	// changes to the spec are planned as updates and deletes
+	b.Annotations = map[string]string{DryRunAnnotation: "true"}
+	b.Spec.BuildStrategies = &v1alpha1.BuildStrategiesSpec{Exclude: []string{"kaniko"}}
+	b.Spec.Overrides = []v1alpha1.ResourceOverride{{
+		Target: v1alpha1.OverrideTarget{Kind: "Deployment", Name: common.BuildControllerDeployment},
+		Patch:  "spec:\n  template:\n    spec:\n      priorityClassName: system-cluster-critical\n",
+	}}
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.Plan.Changes).To(o.ConsistOf(
+		o.And(o.HaveField("Action", v1alpha1.PlanActionUpdate), o.HaveField("Kind", "Deployment"), o.HaveField("Name", common.BuildControllerDeployment),
+			o.HaveField("Fields", o.ContainElement("spec.template.spec.priorityClassName"))),
+		o.And(o.HaveField("Action", v1alpha1.PlanActionDelete), o.HaveField("Kind", "ClusterBuildStrategy"), o.HaveField("Name", "kaniko")),
+	))
+	g.Expect(c.Get(ctx, types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}, d)).To(o.Succeed())
+	g.Expect(d.Spec.Template.Spec.PriorityClassName).To(o.BeEmpty())
+}
+
+func TestShipwrightBuildReconciler_ManagementState(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	b := &v1alpha1.ShipwrightBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "name"},
+		Spec:       v1alpha1.ShipwrightBuildSpec{TargetNamespace: "namespace"},
+	}
+	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: ConditionReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	}
+	crd1 := &crdv1.CustomResourceDefinition{}
+	crd1.Name = "taskruns.tekton.dev"
+	crd2 := &crdv1.CustomResourceDefinition{}
+	crd2.Name = "tektonconfigs.operator.tekton.dev"
+	crd2.Labels = map[string]string{"operator.tekton.dev/release": common.TektonOpMinSupportedVersion}
+	crd3 := &crdv1.CustomResourceDefinition{}
+	crd3.Name = "clusterbuildstrategies.shipwright.io"
+	c, _, _, r := bootstrapShipwrightBuildReconciler(t, b, tektonConfig, []*crdv1.CustomResourceDefinition{crd1, crd2, crd3}, &v1alpha1.ShipwrightBuild{})
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "name"}}
+	deploymentName := types.NamespacedName{Namespace: "namespace", Name: common.BuildControllerDeployment}
+
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.ManagementState).To(o.Equal(v1alpha1.ManagementStateManaged))
+
+	// the components hot-fixed in the Unmanaged state are left as they are
+	d := &appsv1.Deployment{}
+	g.Expect(c.Get(ctx, deploymentName, d)).To(o.Succeed())
+	replicas := int32(3)
+	d.Spec.Replicas = &replicas
+	g.Expect(c.Update(ctx, d)).To(o.Succeed())
+	b.Spec.ManagementState = v1alpha1.ManagementStateUnmanaged
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, deploymentName, d)).To(o.Succeed())
+	g.Expect(*d.Spec.Replicas).To(o.Equal(int32(3)))
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.ManagementState).To(o.Equal(v1alpha1.ManagementStateUnmanaged))
+
+	// the removal is planned in dry-run mode
+	b.Annotations = map[string]string{DryRunAnnotation: "true"}
+	b.Spec.ManagementState = v1alpha1.ManagementStateRemoved
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, deploymentName, d)).To(o.Succeed())
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.Plan).NotTo(o.BeNil())
+	g.Expect(b.Status.Plan.Deletes).To(o.BeNumerically("==", len(b.Status.Plan.Changes)))
+	g.Expect(b.Status.Plan.Changes).To(o.ContainElements(
+		o.And(o.HaveField("Action", v1alpha1.PlanActionDelete), o.HaveField("Kind", "Deployment"), o.HaveField("Name", common.BuildControllerDeployment)),
+		o.And(o.HaveField("Action", v1alpha1.PlanActionDelete), o.HaveField("Kind", "ClusterBuildStrategy"), o.HaveField("Name", "kaniko")),
+	))
+
+	// the components are uninstalled in the Removed state, keeping the ShipwrightBuild
+	b.Annotations = nil
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	err = c.Get(ctx, deploymentName, d)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+	g.Expect(c.Get(ctx, req.NamespacedName, b)).To(o.Succeed())
+	g.Expect(b.Status.ManagementState).To(o.Equal(v1alpha1.ManagementStateRemoved))
+	g.Expect(b.Status.Plan).To(o.BeNil())
+	g.Expect(b.Status.AppliedResources).To(o.BeEmpty())
+	condition := apimeta.FindStatusCondition(b.Status.Conditions, ConditionReady)
+	g.Expect(condition).NotTo(o.BeNil())
+	g.Expect(condition.Status).To(o.Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(o.Equal("Removed"))
+	g.Expect(apimeta.FindStatusCondition(b.Status.Conditions, ConditionBuildControllerReady)).To(o.BeNil())
+
+	// the components are installed again in the Managed state
+	b.Spec.ManagementState = v1alpha1.ManagementStateManaged
+	g.Expect(c.Update(ctx, b)).To(o.Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).To(o.BeNil())
+	g.Expect(c.Get(ctx, deploymentName, d)).To(o.Succeed())
+}
+
+func markDeploymentsAvailable(t *testing.T, c client.Client, namespace string, names ...string) {
+	g := o.NewGomegaWithT(t)
+	for _, name := range names {
+		d := &appsv1.Deployment{}
+		g.Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, d)).To(o.Succeed())
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		d.Status.ObservedGeneration = d.Generation
+		d.Status.Replicas = replicas
+		d.Status.UpdatedReplicas = replicas
+		d.Status.AvailableReplicas = replicas
+		g.Expect(c.Status().Update(context.TODO(), d)).To(o.Succeed())
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	g := o.NewGomegaWithT(t)
+	options := CacheOptions()
+	g.Expect(options.ByObject).To(o.HaveLen(len(ownedObjects())))
+	for obj, byObject := range options.ByObject {
+		g.Expect(byObject.Label.Matches(labels.Set{ShipwrightBuildLabel: "name"})).To(o.BeTrue(), "%T", obj)
+		g.Expect(byObject.Label.Matches(labels.Set{})).To(o.BeFalse(), "%T", obj)
+	}
 }
@@ -30,7 +30,7 @@ package controllers
 // +kubebuilder:rbac:groups=operator.shipwright.io,resources=shipwrightbuilds,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=operator.shipwright.io,resources=shipwrightbuilds/finalizers,verbs=update
 // +kubebuilder:rbac:groups=operator.shipwright.io,resources=shipwrightbuilds/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=operator.tekton.dev,resources=tektonconfigs,verbs=get;list;create
+// +kubebuilder:rbac:groups=operator.tekton.dev,resources=tektonconfigs,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io/v1beta1,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=shipwright-build-webhook,verbs=update;patch;delete
@@ -45,7 +45,28 @@ package controllers
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,resourceNames=shipwright-triggers,verbs=update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,resourceNames=shipwright-triggers,verbs=update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,resourceNames=shipwright-triggers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=cert-manager.io,resources=issuers,resourceNames=selfsigned-issuer,verbs=update;patch;delete
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,resourceNames=shipwright-build-webhook-cert,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,resourceNames=tekton-pipelines-controller;tekton-pipelines-webhook;tekton-events-controller;tekton-pipelines-remote-resolvers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,resourceNames=tekton-pipelines-controller;tekton-pipelines-webhook;tekton-events-controller;tekton-pipelines-resolvers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=list
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces/finalizers,resourceNames=tekton-pipelines,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,resourceNames=tekton-pipelines-webhook,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,resourceNames=webhook.pipeline.tekton.dev,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions;customresourcedefinitions/status,resourceNames=pipelines.tekton.dev;pipelineruns.tekton.dev;tasks.tekton.dev;taskruns.tekton.dev;customruns.tekton.dev;stepactions.tekton.dev;verificationpolicies.tekton.dev;resolutionrequests.resolution.tekton.dev,verbs=get;update;patch;delete
+// +kubebuilder:rbac:groups=tekton.dev,resources=tasks;taskruns;pipelines;pipelineruns;runs;customruns;stepactions;verificationpolicies,verbs=get;list;watch;create;update;patch;delete;deletecollection
+// +kubebuilder:rbac:groups=tekton.dev,resources=taskruns/finalizers;pipelineruns/finalizers;customruns/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tekton.dev,resources=tasks/status;taskruns/status;pipelines/status;pipelineruns/status;customruns/status;verificationpolicies/status;stepactions/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=resolution.tekton.dev,resources=resolutionrequests;resolutionrequests/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=tekton-pipelines-controller-cluster-access;tekton-pipelines-controller-tenant-access;tekton-pipelines-webhook-cluster-access;tekton-events-controller-cluster-access;tekton-aggregate-edit;tekton-aggregate-view;tekton-pipelines-resolvers-resolution-request-updates,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,resourceNames=tekton-pipelines-controller-cluster-access;tekton-pipelines-controller-tenant-access;tekton-pipelines-webhook-cluster-access;tekton-events-controller-cluster-access;tekton-pipelines-resolvers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,resourceNames=tekton-pipelines-controller;tekton-pipelines-webhook;tekton-pipelines-events-controller;tekton-pipelines-leader-election;tekton-pipelines-info;tekton-pipelines-resolvers-namespace-rbac,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,resourceNames=tekton-pipelines-controller;tekton-pipelines-webhook;tekton-pipelines-controller-leaderelection;tekton-pipelines-webhook-leaderelection;tekton-pipelines-info;tekton-pipelines-events-controller;tekton-events-controller-leaderelection;tekton-pipelines-resolvers-namespace-rbac,verbs=update;patch;delete
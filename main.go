@@ -7,6 +7,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -16,6 +17,7 @@ import (
 	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -27,6 +29,7 @@ import (
 
 	operatorv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/controllers"
+	"github.com/shipwright-io/operator/pkg/platform"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -45,6 +48,21 @@ var (
 	webhookPort int
 	// enableLeaderElection enables leader election process, for high-available deployments.
 	enableLeaderElection bool
+	// rolloutTimeout time given to the Shipwright Deployments to roll out before reporting them as
+	// degraded.
+	rolloutTimeout time.Duration
+	// resyncInterval interval at which ShipwrightBuild objects are reconciled again, to repair
+	// changes which were not caught by the watches. Disabled when zero.
+	resyncInterval time.Duration
+	// waitingBackoff requeue backoff of the reconciliations waiting for a precondition.
+	waitingBackoff controllers.Backoff
+	// transientErrorBackoff requeue backoff of the reconciliations failing with a transient error.
+	transientErrorBackoff controllers.Backoff
+	// configurationErrorBackoff requeue backoff of the reconciliations failing with a
+	// configuration error.
+	configurationErrorBackoff controllers.Backoff
+	// requeueJitter maximum jitter factor added to the requeue delays.
+	requeueJitter float64
 )
 
 func init() {
@@ -57,6 +75,29 @@ func init() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&rolloutTimeout, "rollout-timeout", 5*time.Minute,
+		"Time given to the Shipwright Deployments to roll out before reporting them as degraded.")
+	flag.DurationVar(&resyncInterval, "resync-interval", 0,
+		"Interval at which ShipwrightBuild objects are periodically reconciled, disabled when zero.")
+	defaultBackoffs := controllers.DefaultBackoffs()
+	for _, f := range []struct {
+		name    string
+		reason  controllers.RequeueReason
+		backoff *controllers.Backoff
+		usage   string
+	}{
+		{"waiting", controllers.RequeueWaiting, &waitingBackoff, "waiting for a precondition, such as a CRD"},
+		{"transient-error", controllers.RequeueTransientError, &transientErrorBackoff, "failing with a transient error"},
+		{"configuration-error", controllers.RequeueConfigurationError, &configurationErrorBackoff, "failing with a configuration error"},
+	} {
+		*f.backoff = defaultBackoffs[f.reason]
+		flag.DurationVar(&f.backoff.Delay, "requeue-"+f.name+"-delay", f.backoff.Delay,
+			"Initial requeue delay of the reconciliations "+f.usage+", doubled on every attempt.")
+		flag.DurationVar(&f.backoff.MaxDelay, "requeue-"+f.name+"-max-delay", f.backoff.MaxDelay,
+			"Maximum requeue delay of the reconciliations "+f.usage+".")
+	}
+	flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1,
+		"Maximum jitter factor added to the requeue delays.")
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
@@ -65,6 +106,10 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		os.Exit(render(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -73,6 +118,7 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  controllers.CacheOptions(),
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
@@ -99,9 +145,31 @@ func main() {
 		os.Exit(1)
 	}
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to get discovery client")
+		os.Exit(1)
+	}
+	detectedPlatform, err := platform.Detect(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to detect the platform")
+		os.Exit(1)
+	}
+	setupLog.Info("detected platform", "name", detectedPlatform.Name(), "capabilities", detectedPlatform.Capabilities.List())
+
+	requeuePolicy := controllers.NewRequeuePolicy(map[controllers.RequeueReason]controllers.Backoff{
+		controllers.RequeueWaiting:            waitingBackoff,
+		controllers.RequeueTransientError:     transientErrorBackoff,
+		controllers.RequeueConfigurationError: configurationErrorBackoff,
+	}, requeueJitter)
 	if err = (&controllers.ShipwrightBuildReconciler{
 		CRDClient:            crdClient,
 		TektonOperatorClient: tektonOperatorClient,
+		APIReader:            mgr.GetAPIReader(),
+		RolloutTimeout:       rolloutTimeout,
+		ResyncInterval:       resyncInterval,
+		RequeuePolicy:        requeuePolicy,
+		Platform:             detectedPlatform,
 		Client:               mgr.GetClient(),
 		Scheme:               mgr.GetScheme(),
 		Logger:               ctrl.Log.WithName("controllers").WithName("ShipwrightBuild"),
@@ -2,31 +2,133 @@ package buildstrategy
 
 import (
 	"context"
+	"slices"
 
 	"github.com/go-logr/logr"
 	"github.com/manifestival/manifestival"
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/common"
 	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
-const clusterBuildStrategiesCRD = "clusterbuildstrategies.shipwright.io"
+const (
+	clusterBuildStrategiesCRD = "clusterbuildstrategies.shipwright.io"
+	clusterBuildStrategyKind  = "ClusterBuildStrategy"
+)
 
 // ReconcileBuildStrategies reconciles the desired ClusterBuildStrategies to install on the cluster.
-// Returns `true` if the build strategies were not installed and a requeue is required.
-func ReconcileBuildStrategies(ctx context.Context, crdClient crdclientv1.ApiextensionsV1Interface, log logr.Logger, manifest manifestival.Manifest) (bool, error) {
+// Strategies which are not selected by the given spec are removed from the cluster, and changes
+// made to the installed strategies are handled according to their policy.
+// Returns `true` if the build strategies were not installed and a requeue is required, along with
+// the names of the strategies which were modified on the cluster.
+func ReconcileBuildStrategies(ctx context.Context, crdClient crdclientv1.ApiextensionsV1Interface, log logr.Logger, manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) (bool, []string, error) {
 	crdExists, err := common.CRDExist(ctx, crdClient, clusterBuildStrategiesCRD)
 	if err != nil {
-		return true, err
+		return true, nil, err
 	}
 	// If the CRD for Shipwright's cluster build strategies were not installed yet, the reconciler
 	// should requeue.
 	if !crdExists {
-		return true, nil
+		return true, nil, nil
+	}
+	install, remove := SelectBuildStrategies(manifest, spec)
+	// Remove the build strategies that are no longer selected. Objects which were never created,
+	// or which were not installed by the operator, are ignored.
+	remove, err = installedBy(remove)
+	if err != nil {
+		return true, nil, err
+	}
+	if len(remove.Resources()) > 0 {
+		log.V(1).Info("removing unselected build strategies", "strategies", BuildStrategyNames(remove))
+		if err = remove.Delete(); err != nil {
+			return true, nil, err
+		}
+	}
+	install, modified, err := applyPolicies(install, spec)
+	if err != nil {
+		return true, nil, err
+	}
+	if len(modified) > 0 {
+		log.Info("build strategies were modified on the cluster", "strategies", modified)
 	}
 	// Apply the provided manifest containing the build strategies
-	err = manifest.Apply()
+	err = install.Apply()
 	if err != nil {
-		return true, err
+		return true, nil, err
+	}
+	return false, modified, nil
+}
+
+// PlanBuildStrategies returns the build strategies ReconcileBuildStrategies applies and removes,
+// without changing the cluster.
+func PlanBuildStrategies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) (manifestival.Manifest, manifestival.Manifest, error) {
+	install, remove := SelectBuildStrategies(manifest, spec)
+	remove, err := installedBy(remove)
+	if err != nil {
+		return install, remove, err
+	}
+	install, _, err = applyPolicies(install, spec)
+	return install, remove, err
+}
+
+// DeleteBuildStrategies removes the build strategies of the manifest from the cluster, except for
+// the ones which are not managed, or were not installed, by the operator.
+func DeleteBuildStrategies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) error {
+	installed, err := InstalledBuildStrategies(manifest, spec)
+	if err != nil {
+		return err
+	}
+	return installed.Delete()
+}
+
+// InstalledBuildStrategies returns the resources of the manifest which are managed by the
+// operator, according to the given spec, leaving out the ClusterBuildStrategies which were not
+// installed by the operator on the cluster.
+func InstalledBuildStrategies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) (manifestival.Manifest, error) {
+	return installedBy(ManagedBuildStrategies(manifest, spec))
+}
+
+// ManagedBuildStrategies returns the resources of the manifest which are managed by the operator,
+// according to the given spec.
+func ManagedBuildStrategies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) manifestival.Manifest {
+	return manifest.Filter(manifestival.Not(unmanagedBy(spec)))
+}
+
+// SelectBuildStrategies splits the build strategies manifest into the resources to install and
+// the resources to remove, according to the given spec. A nil spec selects every strategy.
+// Resources other than ClusterBuildStrategies are installed unless the strategies are disabled.
+// Strategies which are not managed by the operator are part of neither.
+func SelectBuildStrategies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) (manifestival.Manifest, manifestival.Manifest) {
+	selected := selectedBy(spec)
+	managed := ManagedBuildStrategies(manifest, spec)
+	return managed.Filter(selected), managed.Filter(manifestival.Not(selected))
+}
+
+// BuildStrategyNames returns the names of the ClusterBuildStrategies in the given manifest.
+func BuildStrategyNames(manifest manifestival.Manifest) []string {
+	names := []string{}
+	for _, obj := range manifest.Filter(manifestival.ByKind(clusterBuildStrategyKind)).Resources() {
+		names = append(names, obj.GetName())
+	}
+	slices.Sort(names)
+	return names
+}
+
+func selectedBy(spec *v1alpha1.BuildStrategiesSpec) manifestival.Predicate {
+	return func(u *unstructured.Unstructured) bool {
+		if spec == nil {
+			return true
+		}
+		if spec.State == v1alpha1.BuildStrategiesStateDisabled {
+			return false
+		}
+		if u.GetKind() != clusterBuildStrategyKind {
+			return true
+		}
+		if slices.Contains(spec.Exclude, u.GetName()) {
+			return false
+		}
+		return len(spec.Include) == 0 || slices.Contains(spec.Include, u.GetName())
 	}
-	return false, nil
 }
@@ -5,10 +5,12 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/manifestival/manifestival"
 	. "github.com/onsi/gomega"
 
 	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -17,6 +19,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/common"
 	"github.com/shipwright-io/operator/test"
 )
@@ -69,7 +72,7 @@ func TestReconcileBuildStrategies(t *testing.T) {
 			log := zap.New()
 			manifests, err := common.SetupManifestival(k8sClient, filepath.Join("samples", "buildstrategy"), true, log)
 			o.Expect(err).NotTo(HaveOccurred(), "setting up Manifestival")
-			requeue, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests)
+			requeue, _, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, nil)
 			o.Expect(err).NotTo(HaveOccurred(), "reconciling build strategies")
 			o.Expect(requeue).To(BeEquivalentTo(tc.expectRequeue), "check reconcile requeue")
 
@@ -90,3 +93,110 @@ func TestReconcileBuildStrategies(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectBuildStrategies(t *testing.T) {
+
+	cases := []struct {
+		name               string
+		spec               *v1alpha1.BuildStrategiesSpec
+		expectInstalled    []string
+		expectClusterRoles int
+	}{
+		{
+			name:               "no selection",
+			expectInstalled:    []string{"buildah-shipwright-managed-push", "buildah-strategy-managed-push", "buildkit", "buildpacks-v3", "buildpacks-v3-heroku", "kaniko", "ko", "multiarch-native-buildah", "source-to-image", "source-to-image-redhat"},
+			expectClusterRoles: 1,
+		},
+		{
+			name: "disabled",
+			spec: &v1alpha1.BuildStrategiesSpec{
+				State:   v1alpha1.BuildStrategiesStateDisabled,
+				Include: []string{"buildkit"},
+			},
+			expectInstalled: []string{},
+		},
+		{
+			name: "include",
+			spec: &v1alpha1.BuildStrategiesSpec{
+				State:   v1alpha1.BuildStrategiesStateEnabled,
+				Include: []string{"buildkit", "kaniko"},
+			},
+			expectInstalled:    []string{"buildkit", "kaniko"},
+			expectClusterRoles: 1,
+		},
+		{
+			name: "exclude takes precedence",
+			spec: &v1alpha1.BuildStrategiesSpec{
+				State:   v1alpha1.BuildStrategiesStateEnabled,
+				Include: []string{"buildkit", "kaniko"},
+				Exclude: []string{"kaniko"},
+			},
+			expectInstalled:    []string{"buildkit"},
+			expectClusterRoles: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewWithT(t)
+			k8sClient := fake.NewClientBuilder().Build()
+			manifests, err := common.SetupManifestival(k8sClient, filepath.Join("samples", "buildstrategy"), true, zap.New())
+			o.Expect(err).NotTo(HaveOccurred(), "setting up Manifestival")
+
+			install, remove := SelectBuildStrategies(manifests, tc.spec)
+			o.Expect(BuildStrategyNames(install)).To(Equal(tc.expectInstalled))
+			o.Expect(len(install.Resources()) + len(remove.Resources())).To(Equal(len(manifests.Resources())))
+			o.Expect(install.Filter(manifestival.ByKind("ClusterRole")).Resources()).To(HaveLen(tc.expectClusterRoles))
+		})
+	}
+}
+
+func TestReconcileBuildStrategiesRemovesUnselected(t *testing.T) {
+	o := NewWithT(t)
+	ctx := context.Background()
+	crdClient := apiextensionsfake.NewSimpleClientset(&crdv1.CustomResourceDefinition{
+		ObjectMeta: v1.ObjectMeta{
+			Name: clusterBuildStrategiesCRD,
+		},
+	})
+	schemeBuilder := runtime.NewSchemeBuilder(scheme.AddToScheme, buildv1beta1.AddToScheme)
+	scheme := runtime.NewScheme()
+	err := schemeBuilder.AddToScheme(scheme)
+	o.Expect(err).NotTo(HaveOccurred(), "create k8s client scheme")
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	log := zap.New()
+	manifests, err := common.SetupManifestival(k8sClient, filepath.Join("samples", "buildstrategy"), true, log)
+	o.Expect(err).NotTo(HaveOccurred(), "setting up Manifestival")
+	manifests, err = manifests.Transform(ContentHash())
+	o.Expect(err).NotTo(HaveOccurred(), "stamping content hash")
+
+	// a strategy created by a user with the name of a sample strategy
+	userStrategy := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "buildah-shipwright-managed-push"}}
+	o.Expect(k8sClient.Create(ctx, userStrategy)).To(Succeed(), "creating the user ClusterBuildStrategy")
+	spec := &v1alpha1.BuildStrategiesSpec{
+		State:   v1alpha1.BuildStrategiesStateEnabled,
+		Exclude: []string{userStrategy.Name},
+	}
+	_, _, err = ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, spec)
+	o.Expect(err).NotTo(HaveOccurred(), "installing the selected build strategies")
+
+	spec.Exclude = append(spec.Exclude, "kaniko")
+	requeue, _, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, spec)
+	o.Expect(err).NotTo(HaveOccurred(), "reconciling build strategies")
+	o.Expect(requeue).To(BeFalse(), "check reconcile requeue")
+
+	kaniko := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "kaniko"}}
+	err = k8sClient.Get(ctx, client.ObjectKeyFromObject(kaniko), kaniko)
+	o.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "excluded ClusterBuildStrategy is removed")
+	buildkit := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "buildkit"}}
+	err = k8sClient.Get(ctx, client.ObjectKeyFromObject(buildkit), buildkit)
+	o.Expect(err).NotTo(HaveOccurred(), "selected ClusterBuildStrategy is kept")
+	err = k8sClient.Get(ctx, client.ObjectKeyFromObject(userStrategy), userStrategy)
+	o.Expect(err).NotTo(HaveOccurred(), "ClusterBuildStrategy not installed by the operator is kept")
+
+	o.Expect(DeleteBuildStrategies(manifests, nil)).To(Succeed(), "deleting build strategies")
+	err = k8sClient.Get(ctx, client.ObjectKeyFromObject(buildkit), buildkit)
+	o.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "installed ClusterBuildStrategy is deleted")
+	err = k8sClient.Get(ctx, client.ObjectKeyFromObject(userStrategy), userStrategy)
+	o.Expect(err).NotTo(HaveOccurred(), "ClusterBuildStrategy not installed by the operator is not deleted")
+}
@@ -0,0 +1,195 @@
+package buildstrategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+// ContentHashAnnotation holds the hash of the ClusterBuildStrategy spec applied by the operator.
+const ContentHashAnnotation = "operator.shipwright.io/content-hash"
+
+// ContentHash stamps the ClusterBuildStrategies with the hash of their spec, so that changes made
+// after they were applied can be detected. It should be the last transformer of the manifest.
+func ContentHash() manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != clusterBuildStrategyKind {
+			return nil
+		}
+		hash, err := specHash(u)
+		if err != nil {
+			return err
+		}
+		return common.InjectAnnotations(ContentHashAnnotation, hash, common.Overwrite)(u)
+	}
+}
+
+// unmanagedBy matches the ClusterBuildStrategies which are not managed by the operator according
+// to the given spec.
+func unmanagedBy(spec *v1alpha1.BuildStrategiesSpec) manifestival.Predicate {
+	return func(u *unstructured.Unstructured) bool {
+		return u.GetKind() == clusterBuildStrategyKind &&
+			spec.PolicyFor(u.GetName()) == v1alpha1.BuildStrategyPolicyUnmanaged
+	}
+}
+
+// installedBy filters out the ClusterBuildStrategies of the manifest which were not installed by
+// the operator: the ones missing from the cluster, and the ones without the content hash stamped
+// by the operator, such as strategies created by users with the name of a sample strategy.
+func installedBy(manifest manifestival.Manifest) (manifestival.Manifest, error) {
+	skip := []string{}
+	for _, obj := range manifest.Filter(manifestival.ByKind(clusterBuildStrategyKind)).Resources() {
+		current, err := manifest.Client.Get(&obj)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			skip = append(skip, obj.GetName())
+			continue
+		}
+		if err != nil {
+			return manifest, err
+		}
+		if _, exists := current.GetAnnotations()[ContentHashAnnotation]; !exists {
+			skip = append(skip, obj.GetName())
+		}
+	}
+	if len(skip) == 0 {
+		return manifest, nil
+	}
+	return manifest.Filter(func(u *unstructured.Unstructured) bool {
+		return u.GetKind() != clusterBuildStrategyKind || !slices.Contains(skip, u.GetName())
+	}), nil
+}
+
+// applyPolicies filters out the strategies of the manifest that should not be applied according
+// to their policy, and returns the names of the strategies modified on the cluster. Modified
+// strategies are only applied with the "Overwrite" policy.
+func applyPolicies(manifest manifestival.Manifest, spec *v1alpha1.BuildStrategiesSpec) (manifestival.Manifest, []string, error) {
+	modified := []string{}
+	skip := []string{}
+	for _, obj := range manifest.Filter(manifestival.ByKind(clusterBuildStrategyKind)).Resources() {
+		current, err := manifest.Client.Get(&obj)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return manifest, nil, err
+		}
+		drifted, err := hasDrifted(current)
+		if err != nil {
+			return manifest, nil, err
+		}
+		if !drifted {
+			continue
+		}
+		modified = append(modified, obj.GetName())
+		if spec.PolicyFor(obj.GetName()) == v1alpha1.BuildStrategyPolicyKeepUserChanges {
+			skip = append(skip, obj.GetName())
+		}
+	}
+	if len(skip) == 0 {
+		return manifest, modified, nil
+	}
+	return manifest.Filter(func(u *unstructured.Unstructured) bool {
+		return u.GetKind() != clusterBuildStrategyKind || !slices.Contains(skip, u.GetName())
+	}), modified, nil
+}
+
+// hasDrifted returns true if the given ClusterBuildStrategy was modified since the operator last
+// applied it: either its spec no longer contains the last applied spec, or the last applied
+// configuration does not match the content hash stamped by the operator. Fields added by the API
+// server, such as defaults, are not considered as modifications. Strategies without a content
+// hash are not considered to have drifted.
+func hasDrifted(u *unstructured.Unstructured) (bool, error) {
+	expected, exists := u.GetAnnotations()[ContentHashAnnotation]
+	if !exists {
+		return false, nil
+	}
+	lastApplied, exists := u.GetAnnotations()[corev1.LastAppliedConfigAnnotation]
+	if !exists {
+		return true, nil
+	}
+	applied := &unstructured.Unstructured{}
+	if err := applied.UnmarshalJSON([]byte(lastApplied)); err != nil {
+		return false, fmt.Errorf("parsing last applied configuration of %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	hash, err := specHash(applied)
+	if err != nil {
+		return false, err
+	}
+	if hash != expected {
+		return true, nil
+	}
+	return !containsFields(u.Object["spec"], applied.Object["spec"]), nil
+}
+
+// containsFields returns true if every field set in expected has the same value in actual. Fields
+// missing from actual are equal to their zero value, as they may be omitted when empty.
+func containsFields(actual, expected interface{}) bool {
+	if actual == nil {
+		return isEmpty(expected)
+	}
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range expected {
+			if !containsFields(actual[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok || len(actual) != len(expected) {
+			return false
+		}
+		for i := range expected {
+			if !containsFields(actual[i], expected[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func specHash(u *unstructured.Unstructured) (string, error) {
+	spec, _, err := unstructured.NestedFieldNoCopy(u.Object, "spec")
+	if err != nil {
+		return "", err
+	}
+	// Maps are marshalled with sorted keys, which keeps the hash stable.
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("hashing %s %s: %w", u.GetKind(), u.GetName(), err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
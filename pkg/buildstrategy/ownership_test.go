@@ -0,0 +1,135 @@
+package buildstrategy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	crdv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+func TestContentHash(t *testing.T) {
+	o := NewWithT(t)
+	manifests, err := common.SetupManifestival(fake.NewClientBuilder().Build(), filepath.Join("samples", "buildstrategy"), true, zap.New())
+	o.Expect(err).NotTo(HaveOccurred(), "setting up Manifestival")
+	manifests, err = manifests.Transform(ContentHash())
+	o.Expect(err).NotTo(HaveOccurred(), "stamping content hash")
+
+	for _, obj := range manifests.Resources() {
+		hash, stamped := obj.GetAnnotations()[ContentHashAnnotation]
+		o.Expect(stamped).To(Equal(obj.GetKind() == clusterBuildStrategyKind), "content hash on %s %s", obj.GetKind(), obj.GetName())
+		if stamped {
+			expected, err := specHash(&obj)
+			o.Expect(err).NotTo(HaveOccurred())
+			o.Expect(hash).To(Equal(expected), "content hash of %s", obj.GetName())
+		}
+	}
+}
+
+func TestReconcileBuildStrategiesPolicies(t *testing.T) {
+	o := NewWithT(t)
+	ctx := context.Background()
+	crdClient := apiextensionsfake.NewSimpleClientset(&crdv1.CustomResourceDefinition{
+		ObjectMeta: v1.ObjectMeta{
+			Name: clusterBuildStrategiesCRD,
+		},
+	})
+	schemeBuilder := runtime.NewSchemeBuilder(scheme.AddToScheme, buildv1beta1.AddToScheme)
+	scheme := runtime.NewScheme()
+	err := schemeBuilder.AddToScheme(scheme)
+	o.Expect(err).NotTo(HaveOccurred(), "create k8s client scheme")
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	log := zap.New()
+	manifests, err := common.SetupManifestival(k8sClient, filepath.Join("samples", "buildstrategy"), true, log)
+	o.Expect(err).NotTo(HaveOccurred(), "setting up Manifestival")
+	manifests, err = manifests.Transform(ContentHash())
+	o.Expect(err).NotTo(HaveOccurred(), "stamping content hash")
+
+	_, modified, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, nil)
+	o.Expect(err).NotTo(HaveOccurred(), "installing build strategies")
+	o.Expect(modified).To(BeEmpty(), "no strategy modified on install")
+
+	// Simulate edits made by a platform team
+	for _, name := range []string{"buildkit", "kaniko"} {
+		modifyStrategy(ctx, o, k8sClient, name)
+	}
+
+	spec := &v1alpha1.BuildStrategiesSpec{
+		Policy: v1alpha1.BuildStrategyPolicyOverwrite,
+		Policies: map[string]v1alpha1.BuildStrategyPolicy{
+			"kaniko": v1alpha1.BuildStrategyPolicyKeepUserChanges,
+		},
+	}
+	requeue, modified, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, spec)
+	o.Expect(err).NotTo(HaveOccurred(), "reconciling build strategies")
+	o.Expect(requeue).To(BeFalse(), "check reconcile requeue")
+	o.Expect(modified).To(ConsistOf("buildkit", "kaniko"), "modified strategies are reported")
+
+	o.Expect(firstStepImage(ctx, o, k8sClient, "buildkit")).NotTo(Equal(modifiedImage), "Overwrite reverts changes")
+	o.Expect(firstStepImage(ctx, o, k8sClient, "kaniko")).To(Equal(modifiedImage), "KeepUserChanges keeps changes")
+
+	t.Run("unmanaged strategies are left alone", func(t *testing.T) {
+		o := NewWithT(t)
+		spec := &v1alpha1.BuildStrategiesSpec{
+			State:  v1alpha1.BuildStrategiesStateDisabled,
+			Policy: v1alpha1.BuildStrategyPolicyOverwrite,
+			Policies: map[string]v1alpha1.BuildStrategyPolicy{
+				"kaniko": v1alpha1.BuildStrategyPolicyUnmanaged,
+			},
+		}
+		_, _, err := ReconcileBuildStrategies(ctx, crdClient.ApiextensionsV1(), log, manifests, spec)
+		o.Expect(err).NotTo(HaveOccurred(), "reconciling build strategies")
+		o.Expect(firstStepImage(ctx, o, k8sClient, "kaniko")).To(Equal(modifiedImage), "unmanaged strategy is kept")
+
+		err = DeleteBuildStrategies(manifests, spec)
+		o.Expect(err).NotTo(HaveOccurred(), "deleting build strategies")
+		kaniko := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "kaniko"}}
+		o.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kaniko), kaniko)).To(Succeed(), "unmanaged strategy is not deleted")
+		buildkit := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "buildkit"}}
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(buildkit), buildkit)
+		o.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "managed strategy is deleted")
+	})
+}
+
+const modifiedImage = "registry.example.com/hardened/builder:latest"
+
+func modifyStrategy(ctx context.Context, o *WithT, k8sClient client.Client, name string) {
+	obj := getStrategy(ctx, o, k8sClient, name)
+	steps, _, err := unstructured.NestedSlice(obj.Object, "spec", "steps")
+	o.Expect(err).NotTo(HaveOccurred())
+	steps[0].(map[string]interface{})["image"] = modifiedImage
+	o.Expect(unstructured.SetNestedSlice(obj.Object, steps, "spec", "steps")).To(Succeed())
+	o.Expect(k8sClient.Update(ctx, obj)).To(Succeed(), "update ClusterBuildStrategy %s", name)
+}
+
+func firstStepImage(ctx context.Context, o *WithT, k8sClient client.Client, name string) string {
+	obj := getStrategy(ctx, o, k8sClient, name)
+	steps, _, err := unstructured.NestedSlice(obj.Object, "spec", "steps")
+	o.Expect(err).NotTo(HaveOccurred())
+	image, _, _ := unstructured.NestedString(steps[0].(map[string]interface{}), "image")
+	return image
+}
+
+func getStrategy(ctx context.Context, o *WithT, k8sClient client.Client, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(buildv1beta1.SchemeGroupVersion.String())
+	obj.SetKind(clusterBuildStrategyKind)
+	err := k8sClient.Get(ctx, client.ObjectKey{Name: name}, obj)
+	o.Expect(err).NotTo(HaveOccurred(), "get ClusterBuildStrategy %s", name)
+	return obj
+}
@@ -0,0 +1,237 @@
+package buildstrategy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+const (
+	// SourceLabel marks the ClusterBuildStrategies installed from a build strategy source.
+	SourceLabel = "operator.shipwright.io/build-strategy-source"
+	// SourceAnnotation references the source a ClusterBuildStrategy was installed from, in the
+	// "<kind>/<namespace>/<name>" form.
+	SourceAnnotation = "operator.shipwright.io/build-strategy-source-ref"
+
+	shipwrightGroup = "shipwright.io"
+)
+
+// LoadSources reads the ClusterBuildStrategies of the sources listed in the given spec. Sources
+// default to the given namespace. Strategies named after one of the reserved names, or defined by
+// several sources, are rejected. Sources which cannot be loaded are reported in their status and
+// skipped. The sources are read with the given reader, which should not be cached, to avoid caching
+// every ConfigMap and Secret of the cluster.
+func LoadSources(ctx context.Context, c client.Reader, spec *v1alpha1.BuildStrategiesSpec, defaultNamespace string, reserved []string) ([]unstructured.Unstructured, []v1alpha1.BuildStrategySourceStatus) {
+	strategies := []unstructured.Unstructured{}
+	if spec == nil || len(spec.Sources) == 0 {
+		return strategies, nil
+	}
+
+	statuses := []v1alpha1.BuildStrategySourceStatus{}
+	names := slices.Clone(reserved)
+	for _, source := range spec.Sources {
+		kind, ref := sourceReference(source)
+		status := v1alpha1.BuildStrategySourceStatus{
+			Kind:      kind,
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		}
+		if status.Namespace == "" {
+			status.Namespace = defaultNamespace
+		}
+
+		loaded, err := loadSource(ctx, c, kind, status.Namespace, ref)
+		if err == nil {
+			err = checkNames(loaded, names)
+		}
+		if err != nil {
+			status.Error = err.Error()
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status.BuildStrategies = []string{}
+		for i := range loaded {
+			labels := loaded[i].GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[SourceLabel] = "true"
+			loaded[i].SetLabels(labels)
+			annotations := loaded[i].GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[SourceAnnotation] = sourceKey(status)
+			loaded[i].SetAnnotations(annotations)
+
+			names = append(names, loaded[i].GetName())
+			status.BuildStrategies = append(status.BuildStrategies, loaded[i].GetName())
+		}
+		strategies = append(strategies, loaded...)
+		statuses = append(statuses, status)
+	}
+	return strategies, statuses
+}
+
+// PruneSourceStrategies deletes the ClusterBuildStrategies installed from a source which are not
+// part of the given manifest anymore. Strategies of the sources which could not be loaded are kept,
+// as well as the strategies which are not managed by the operator. The installed strategies are
+// listed with the given reader.
+func PruneSourceStrategies(ctx context.Context, reader client.Reader, c client.Client, manifest manifestival.Manifest, statuses []v1alpha1.BuildStrategySourceStatus, spec *v1alpha1.BuildStrategiesSpec) error {
+	pruned, err := PrunedSourceStrategies(ctx, reader, manifest, statuses, spec)
+	if err != nil {
+		return err
+	}
+	for i := range pruned {
+		if err := c.Delete(ctx, &pruned[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrunedSourceStrategies returns the ClusterBuildStrategies PruneSourceStrategies deletes.
+func PrunedSourceStrategies(ctx context.Context, c client.Reader, manifest manifestival.Manifest, statuses []v1alpha1.BuildStrategySourceStatus, spec *v1alpha1.BuildStrategiesSpec) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   shipwrightGroup,
+		Version: "v1beta1",
+		Kind:    clusterBuildStrategyKind + "List",
+	})
+	err := c.List(ctx, list, client.HasLabels{SourceLabel})
+	if meta.IsNoMatchError(err) {
+		// The ClusterBuildStrategy CRD is not installed, there is nothing to prune.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	desired := BuildStrategyNames(manifest)
+	failed := []string{}
+	for _, status := range statuses {
+		if status.Error != "" {
+			failed = append(failed, sourceKey(status))
+		}
+	}
+	pruned := []unstructured.Unstructured{}
+	for _, obj := range list.Items {
+		if slices.Contains(desired, obj.GetName()) ||
+			slices.Contains(failed, obj.GetAnnotations()[SourceAnnotation]) ||
+			spec.PolicyFor(obj.GetName()) == v1alpha1.BuildStrategyPolicyUnmanaged {
+			continue
+		}
+		pruned = append(pruned, obj)
+	}
+	return pruned, nil
+}
+
+func sourceReference(source v1alpha1.BuildStrategySource) (string, *v1alpha1.BuildStrategySourceReference) {
+	if source.Secret != nil {
+		return "Secret", source.Secret
+	}
+	if source.ConfigMap != nil {
+		return "ConfigMap", source.ConfigMap
+	}
+	return "ConfigMap", &v1alpha1.BuildStrategySourceReference{}
+}
+
+func sourceKey(status v1alpha1.BuildStrategySourceStatus) string {
+	return strings.Join([]string{status.Kind, status.Namespace, status.Name}, "/")
+}
+
+// loadSource reads and validates the ClusterBuildStrategies held by a ConfigMap or Secret.
+func loadSource(ctx context.Context, c client.Reader, kind, namespace string, ref *v1alpha1.BuildStrategySourceReference) ([]unstructured.Unstructured, error) {
+	if ref.Name == "" {
+		return nil, fmt.Errorf("exactly one of configMap or secret must be set")
+	}
+	key := client.ObjectKey{Namespace: namespace, Name: ref.Name}
+	data := map[string][]byte{}
+	switch kind {
+	case "Secret":
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, err
+		}
+		data = secret.Data
+	default:
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, key, configMap); err != nil {
+			return nil, err
+		}
+		for k, v := range configMap.Data {
+			data[k] = []byte(v)
+		}
+	}
+
+	keys := []string{}
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	if ref.Key != "" {
+		if _, exists := data[ref.Key]; !exists {
+			return nil, fmt.Errorf("key %q not found in %s %s", ref.Key, kind, key)
+		}
+		keys = []string{ref.Key}
+	}
+
+	strategies := []unstructured.Unstructured{}
+	for _, k := range keys {
+		resources, err := manifestival.Reader(bytes.NewReader(data[k])).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %q of %s %s: %w", k, kind, key, err)
+		}
+		for _, obj := range resources {
+			if err := validateStrategy(&obj); err != nil {
+				return nil, fmt.Errorf("key %q of %s %s: %w", k, kind, key, err)
+			}
+		}
+		strategies = append(strategies, resources...)
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("no ClusterBuildStrategy found in %s %s", kind, key)
+	}
+	return strategies, nil
+}
+
+// validateStrategy only accepts ClusterBuildStrategies, so that a source cannot be used to install
+// arbitrary objects with the operator's permissions.
+func validateStrategy(obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != shipwrightGroup || gvk.Kind != clusterBuildStrategyKind {
+		return fmt.Errorf("unsupported object %s %q, only %s objects are allowed", gvk.Kind, obj.GetName(), clusterBuildStrategyKind)
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("%s without a name", clusterBuildStrategyKind)
+	}
+	if _, found, _ := unstructured.NestedSlice(obj.Object, "spec", "steps"); !found {
+		return fmt.Errorf("%s %q has no steps", clusterBuildStrategyKind, obj.GetName())
+	}
+	return nil
+}
+
+// checkNames rejects the strategies named after one of the given names, or defined twice.
+func checkNames(strategies []unstructured.Unstructured, names []string) error {
+	seen := slices.Clone(names)
+	for _, obj := range strategies {
+		if slices.Contains(seen, obj.GetName()) {
+			return fmt.Errorf("%s %q is already defined", clusterBuildStrategyKind, obj.GetName())
+		}
+		seen = append(seen, obj.GetName())
+	}
+	return nil
+}
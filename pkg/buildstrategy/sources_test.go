@@ -0,0 +1,202 @@
+package buildstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+const hardenedStrategies = `apiVersion: shipwright.io/v1beta1
+kind: ClusterBuildStrategy
+metadata:
+  name: buildah-hardened
+spec:
+  steps:
+    - name: build
+      image: quay.io/containers/buildah:v1.43.1
+---
+apiVersion: shipwright.io/v1beta1
+kind: ClusterBuildStrategy
+metadata:
+  name: buildah-hardened-push
+spec:
+  steps:
+    - name: build-and-push
+      image: quay.io/containers/buildah:v1.43.1
+`
+
+const clusterRole = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: escalate
+rules:
+  - apiGroups: ["*"]
+    resources: ["*"]
+    verbs: ["*"]
+`
+
+func TestLoadSources(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: "hardened", Namespace: "shipwright-build"},
+			Data:       map[string]string{"strategies.yaml": hardenedStrategies, "README": ""},
+		},
+		&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: "private", Namespace: "platform"},
+			Data:       map[string][]byte{"strategies.yaml": []byte(hardenedStrategies)},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: "escalate", Namespace: "shipwright-build"},
+			Data:       map[string]string{"strategies.yaml": clusterRole},
+		},
+	).Build()
+
+	cases := []struct {
+		name             string
+		source           v1alpha1.BuildStrategySource
+		reserved         []string
+		expectStrategies []string
+		expectError      string
+	}{
+		{
+			name:             "configmap in the target namespace",
+			source:           v1alpha1.BuildStrategySource{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "hardened"}},
+			expectStrategies: []string{"buildah-hardened", "buildah-hardened-push"},
+		},
+		{
+			name: "secret key",
+			source: v1alpha1.BuildStrategySource{Secret: &v1alpha1.BuildStrategySourceReference{
+				Name:      "private",
+				Namespace: "platform",
+				Key:       "strategies.yaml",
+			}},
+			expectStrategies: []string{"buildah-hardened", "buildah-hardened-push"},
+		},
+		{
+			name:        "missing source",
+			source:      v1alpha1.BuildStrategySource{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "missing"}},
+			expectError: "not found",
+		},
+		{
+			name:        "missing key",
+			source:      v1alpha1.BuildStrategySource{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "hardened", Key: "missing"}},
+			expectError: `key "missing" not found`,
+		},
+		{
+			name:        "objects other than strategies",
+			source:      v1alpha1.BuildStrategySource{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "escalate"}},
+			expectError: `unsupported object ClusterRole "escalate"`,
+		},
+		{
+			name:        "reserved names",
+			source:      v1alpha1.BuildStrategySource{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "hardened"}},
+			reserved:    []string{"buildah-hardened"},
+			expectError: `ClusterBuildStrategy "buildah-hardened" is already defined`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewWithT(t)
+			spec := &v1alpha1.BuildStrategiesSpec{Sources: []v1alpha1.BuildStrategySource{tc.source}}
+			strategies, statuses := LoadSources(context.Background(), k8sClient, spec, "shipwright-build", tc.reserved)
+			o.Expect(statuses).To(HaveLen(1))
+			if tc.expectError != "" {
+				o.Expect(statuses[0].Error).To(ContainSubstring(tc.expectError))
+				o.Expect(strategies).To(BeEmpty())
+				return
+			}
+			o.Expect(statuses[0].Error).To(BeEmpty())
+			o.Expect(statuses[0].BuildStrategies).To(Equal(tc.expectStrategies))
+			o.Expect(strategies).To(HaveLen(len(tc.expectStrategies)))
+			for _, obj := range strategies {
+				o.Expect(obj.GetLabels()).To(HaveKeyWithValue(SourceLabel, "true"))
+				o.Expect(obj.GetAnnotations()).To(HaveKeyWithValue(SourceAnnotation, sourceKey(statuses[0])))
+			}
+		})
+	}
+
+	t.Run("strategies defined by several sources", func(t *testing.T) {
+		o := NewWithT(t)
+		spec := &v1alpha1.BuildStrategiesSpec{Sources: []v1alpha1.BuildStrategySource{
+			{ConfigMap: &v1alpha1.BuildStrategySourceReference{Name: "hardened"}},
+			{Secret: &v1alpha1.BuildStrategySourceReference{Name: "private", Namespace: "platform"}},
+		}}
+		strategies, statuses := LoadSources(context.Background(), k8sClient, spec, "shipwright-build", nil)
+		o.Expect(strategies).To(HaveLen(2))
+		o.Expect(statuses).To(HaveLen(2))
+		o.Expect(statuses[0].Error).To(BeEmpty())
+		o.Expect(statuses[1].Error).To(ContainSubstring("already defined"))
+	})
+}
+
+func TestPruneSourceStrategies(t *testing.T) {
+	o := NewWithT(t)
+	ctx := context.Background()
+	schemeBuilder := runtime.NewSchemeBuilder(scheme.AddToScheme, buildv1beta1.AddToScheme)
+	scheme := runtime.NewScheme()
+	err := schemeBuilder.AddToScheme(scheme)
+	o.Expect(err).NotTo(HaveOccurred(), "create k8s client scheme")
+
+	sourceStrategy := func(name, source string) *buildv1beta1.ClusterBuildStrategy {
+		return &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{SourceLabel: "true"},
+			Annotations: map[string]string{SourceAnnotation: source},
+		}}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		sourceStrategy("kept", "ConfigMap/shipwright-build/hardened"),
+		sourceStrategy("removed", "ConfigMap/shipwright-build/hardened"),
+		sourceStrategy("failing", "ConfigMap/shipwright-build/broken"),
+		sourceStrategy("unmanaged", "ConfigMap/shipwright-build/legacy"),
+		&buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: "buildkit"}},
+	).Build()
+
+	kept := unstructured.Unstructured{}
+	kept.SetAPIVersion(buildv1beta1.SchemeGroupVersion.String())
+	kept.SetKind(clusterBuildStrategyKind)
+	kept.SetName("kept")
+	manifest, err := manifestival.ManifestFrom(manifestival.Slice{kept})
+	o.Expect(err).NotTo(HaveOccurred())
+	statuses := []v1alpha1.BuildStrategySourceStatus{
+		{Kind: "ConfigMap", Namespace: "shipwright-build", Name: "hardened", BuildStrategies: []string{"kept"}},
+		{Kind: "ConfigMap", Namespace: "shipwright-build", Name: "broken", Error: "parse error"},
+	}
+	spec := &v1alpha1.BuildStrategiesSpec{Policies: map[string]v1alpha1.BuildStrategyPolicy{
+		"unmanaged": v1alpha1.BuildStrategyPolicyUnmanaged,
+	}}
+
+	err = PruneSourceStrategies(ctx, k8sClient, k8sClient, manifest, statuses, spec)
+	o.Expect(err).NotTo(HaveOccurred(), "pruning source strategies")
+
+	for name, exists := range map[string]bool{
+		"kept":      true,
+		"removed":   false,
+		"failing":   true,
+		"unmanaged": true,
+		"buildkit":  true,
+	} {
+		obj := &buildv1beta1.ClusterBuildStrategy{ObjectMeta: v1.ObjectMeta{Name: name}}
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if exists {
+			o.Expect(err).NotTo(HaveOccurred(), "ClusterBuildStrategy %s is kept", name)
+		} else {
+			o.Expect(apierrors.IsNotFound(err)).To(BeTrue(), "ClusterBuildStrategy %s is removed", name)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	mf "github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+const (
+	// WebhookService is the Service of the Shipwright Build webhook.
+	WebhookService = "shp-build-webhook"
+	// WebhookSecret is the Secret holding the webhook certificates, mounted by the webhook
+	// Deployment.
+	WebhookSecret = "shipwright-build-webhook-cert"
+	// CACertKey is the key of the CA certificate in the webhook Secret.
+	CACertKey = "ca.crt"
+	// caKeyKey is the key of the CA private key in the webhook Secret.
+	caKeyKey = "ca.key"
+	// previousCACertKey is the key of the rotated CA certificate in the webhook Secret, kept until
+	// the webhook serves a certificate of the new CA.
+	previousCACertKey = "previous-ca.crt"
+	// CertificateHashAnnotation is set on the webhook pod template with the hash of the serving
+	// certificate, so that the webhook is restarted when the certificate is rotated.
+	CertificateHashAnnotation = "operator.shipwright.io/webhook-certificate-hash"
+
+	// caValidity is the validity of the generated CA.
+	caValidity = 10 * 365 * 24 * time.Hour
+	// servingValidity is the validity of the generated serving certificate.
+	servingValidity = 365 * 24 * time.Hour
+)
+
+// WebhookDNSNames returns the DNS names of the webhook Service in the given namespace.
+func WebhookDNSNames(namespace string) []string {
+	return []string{fmt.Sprintf("%s.%s.svc", WebhookService, namespace)}
+}
+
+// BuiltIn describes the certificates generated by the operator.
+type BuiltIn struct {
+	// CABundle is the PEM encoded CA certificate, which signs the serving certificate, followed by
+	// the rotated CA certificate until the webhook Deployment is rolled out with the new serving
+	// certificate.
+	CABundle []byte
+	// NotAfter is the expiry of the serving certificate.
+	NotAfter time.Time
+	// RenewAt is the time at which the certificates are rotated, when a third of the validity of
+	// the serving certificate or the CA is left.
+	RenewAt time.Time
+	// Hash identifies the serving certificate.
+	Hash string
+}
+
+// ReconcileBuiltIn ensures that the webhook Secret of the namespace holds a CA and a serving
+// certificate for the given DNS names, valid at the given time. The CA and the serving
+// certificate are generated when missing, invalid or due for renewal, otherwise the existing ones
+// are kept. When the CA is rotated, the previous CA stays in the bundle until the webhook Deployment
+// is rolled out with the new serving certificate, so that the API server trusts the webhook pods
+// of both certificates meanwhile. The Secret and the webhook Deployment are read with the given
+// reader, which should not be cached, to avoid caching every Secret of the cluster.
+func ReconcileBuiltIn(ctx context.Context, reader client.Reader, c client.Client, namespace string, dnsNames []string, labels map[string]string, now time.Time) (*BuiltIn, error) {
+	secret := &corev1.Secret{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: WebhookSecret}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	ca, caKey, err := parseKeyPair(secret.Data[CACertKey], secret.Data[caKeyKey])
+	validCA := err == nil && ca.IsCA
+	renewCA := !validCA || !now.Before(renewAt(ca))
+	previousCA := secret.Data[previousCACertKey]
+	if renewCA {
+		previousCA = nil
+		if validCA && now.Before(ca.NotAfter) {
+			previousCA = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})
+		}
+		ca, caKey, err = generateCA(now)
+		if err != nil {
+			return nil, fmt.Errorf("generating the webhook CA: %w", err)
+		}
+	}
+	serving, servingKey, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if renewCA || err != nil || !validServingCertificate(serving, ca, dnsNames, now) {
+		serving, servingKey, err = generateServingCertificate(ca, caKey, dnsNames, now)
+		if err != nil {
+			return nil, fmt.Errorf("generating the webhook serving certificate: %w", err)
+		}
+	}
+
+	if previousCA != nil {
+		rolledOut, err := webhookRolledOut(ctx, reader, namespace, certificateHash(serving))
+		if err != nil {
+			return nil, err
+		}
+		if rolledOut {
+			previousCA = nil
+		}
+	}
+
+	data, err := secretData(ca, caKey, serving, servingKey, previousCA)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: WebhookSecret, Labels: labels},
+			Type:       corev1.SecretTypeTLS,
+			Data:       data,
+		}
+		if err := c.Create(ctx, secret); err != nil {
+			return nil, err
+		}
+	} else if !secretDataEqual(secret.Data, data) || !labelsSet(secret.Labels, labels) {
+		secret.Data = data
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		for key, value := range labels {
+			secret.Labels[key] = value
+		}
+		if err := c.Update(ctx, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return newBuiltIn(ca, serving, data), nil
+}
+
+// GetBuiltIn returns the certificates of the webhook Secret of the namespace, as they are, without
+// generating nor rotating them. It returns a NotFound error when the Secret does not exist.
+func GetBuiltIn(ctx context.Context, reader client.Reader, namespace string) (*BuiltIn, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: WebhookSecret}, secret); err != nil {
+		return nil, err
+	}
+	ca, _, err := parseKeyPair(secret.Data[CACertKey], secret.Data[caKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("parsing the webhook CA of Secret %s/%s: %w", namespace, WebhookSecret, err)
+	}
+	serving, _, err := parseKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("parsing the webhook serving certificate of Secret %s/%s: %w", namespace, WebhookSecret, err)
+	}
+	return newBuiltIn(ca, serving, secret.Data), nil
+}
+
+// newBuiltIn describes the certificates of the webhook Secret data.
+func newBuiltIn(ca, serving *x509.Certificate, data map[string][]byte) *BuiltIn {
+	return &BuiltIn{
+		CABundle: append(append([]byte{}, data[CACertKey]...), data[previousCACertKey]...),
+		NotAfter: serving.NotAfter,
+		RenewAt:  minTime(renewAt(ca), renewAt(serving)),
+		Hash:     certificateHash(serving),
+	}
+}
+
+// certificateHash identifies the certificate.
+func certificateHash(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(hash[:8])
+}
+
+// webhookRolledOut checks that all the pods of the webhook Deployment serve the certificate with
+// the given hash. No pod serves a previous certificate when the Deployment does not exist.
+func webhookRolledOut(ctx context.Context, reader client.Reader, namespace, hash string) (bool, error) {
+	d := &appsv1.Deployment{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: common.BuildWebhookDeployment}, d)
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if d.Spec.Template.Annotations[CertificateHashAnnotation] != hash {
+		return false, nil
+	}
+	complete, _ := common.DeploymentRolloutStatus(d)
+	return complete, nil
+}
+
+// InjectCABundle sets the given CA bundle on the conversion webhook of the CRDs.
+func InjectCABundle(caBundle []byte) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "CustomResourceDefinition" {
+			return nil
+		}
+		_, found, err := unstructured.NestedMap(u.Object, "spec", "conversion", "webhook", "clientConfig")
+		if err != nil || !found {
+			return err
+		}
+		return unstructured.SetNestedField(u.Object, base64.StdEncoding.EncodeToString(caBundle),
+			"spec", "conversion", "webhook", "clientConfig", "caBundle")
+	}
+}
+
+// InjectCertificateHash sets the hash of the serving certificate on the pod template of the named
+// Deployment, so that it is rolled out again when the certificate is rotated.
+func InjectCertificateHash(deployment, hash string) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "Deployment" || u.GetName() != deployment {
+			return nil
+		}
+		annotations, _, err := unstructured.NestedStringMap(u.Object, "spec", "template", "metadata", "annotations")
+		if err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[CertificateHashAnnotation] = hash
+		return unstructured.SetNestedStringMap(u.Object, annotations, "spec", "template", "metadata", "annotations")
+	}
+}
+
+// validServingCertificate checks that the serving certificate is signed by the CA, covers the DNS
+// names and is not due for renewal.
+func validServingCertificate(serving, ca *x509.Certificate, dnsNames []string, now time.Time) bool {
+	if serving.CheckSignatureFrom(ca) != nil || !now.Before(renewAt(serving)) {
+		return false
+	}
+	for _, name := range dnsNames {
+		if !slices.Contains(serving.DNSNames, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// renewAt returns the time at which a third of the validity of the certificate is left.
+func renewAt(cert *x509.Certificate) time.Time {
+	return cert.NotAfter.Add(-cert.NotAfter.Sub(cert.NotBefore) / 3)
+}
+
+func generateCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "shipwright-build-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return generateCertificate(template, nil, nil)
+}
+
+func generateServingCertificate(ca *x509.Certificate, caKey *ecdsa.PrivateKey, dnsNames []string, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	commonName := ""
+	if len(dnsNames) > 0 {
+		commonName = dnsNames[0]
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    minTime(now.Add(servingValidity), ca.NotAfter),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return generateCertificate(template, ca, caKey)
+}
+
+// generateCertificate creates a certificate with a new key from the template, signed by the
+// parent, or self-signed when the parent is nil.
+func generateCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// parseKeyPair parses the PEM encoded certificate and EC private key.
+func parseKeyPair(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, fmt.Errorf("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func secretData(ca *x509.Certificate, caKey *ecdsa.PrivateKey, serving *x509.Certificate, servingKey *ecdsa.PrivateKey, previousCA []byte) (map[string][]byte, error) {
+	caKeyDER, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		return nil, err
+	}
+	servingKeyDER, err := x509.MarshalECPrivateKey(servingKey)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		CACertKey:               pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}),
+		caKeyKey:                pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER}),
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serving.Raw}),
+		corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: servingKeyDER}),
+	}
+	if previousCA != nil {
+		data[previousCACertKey] = previousCA
+	}
+	return data, nil
+}
+
+func secretDataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if !bytes.Equal(value, b[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// labelsSet checks that the labels contain all the expected ones.
+func labelsSet(labels, expected map[string]string) bool {
+	for key, value := range expected {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
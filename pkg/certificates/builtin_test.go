@@ -0,0 +1,209 @@
+package certificates
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	o "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+func parseCertificate(g *o.WithT, data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	g.Expect(block).NotTo(o.BeNil())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	return cert
+}
+
+func getSecret(g *o.WithT, c client.Client) *corev1.Secret {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: "shipwright-build", Name: WebhookSecret}, secret)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	return secret
+}
+
+func TestReconcileBuiltIn(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(s)).To(o.Succeed())
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	dnsNames := WebhookDNSNames("shipwright-build")
+	labels := map[string]string{"operator.shipwright.io/shipwrightbuild": "cluster"}
+	now := time.Now()
+
+	// the CA and serving certificate are generated
+	certs, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, labels, now)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	secret := getSecret(g, c)
+	g.Expect(secret.Type).To(o.Equal(corev1.SecretTypeTLS))
+	g.Expect(secret.Labels).To(o.Equal(labels))
+	g.Expect(certs.CABundle).To(o.Equal(secret.Data[CACertKey]))
+
+	ca := parseCertificate(g, secret.Data[CACertKey])
+	serving := parseCertificate(g, secret.Data[corev1.TLSCertKey])
+	g.Expect(ca.IsCA).To(o.BeTrue())
+	g.Expect(serving.DNSNames).To(o.Equal([]string{"shp-build-webhook.shipwright-build.svc"}))
+	g.Expect(serving.CheckSignatureFrom(ca)).To(o.Succeed())
+	g.Expect(certs.NotAfter).To(o.BeTemporally("==", serving.NotAfter))
+	g.Expect(certs.RenewAt).To(o.BeTemporally("~", now.Add(servingValidity*2/3), 2*time.Hour))
+
+	// the certificates are read as they are
+	got, err := GetBuiltIn(ctx, c, "shipwright-build")
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(got).To(o.Equal(certs))
+	_, err = GetBuiltIn(ctx, c, "other")
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+
+	// valid certificates are kept
+	kept, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, labels, now.Add(24*time.Hour))
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(kept).To(o.Equal(certs))
+	g.Expect(getSecret(g, c).Data).To(o.Equal(secret.Data))
+
+	// the serving certificate is rotated before it expires, with the same CA
+	rotated, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, labels, certs.RenewAt)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(rotated.Hash).NotTo(o.Equal(certs.Hash))
+	g.Expect(rotated.CABundle).To(o.Equal(certs.CABundle))
+	g.Expect(rotated.NotAfter).To(o.BeTemporally(">", certs.NotAfter))
+
+	// the serving certificate is issued again when the DNS names change
+	renamed, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", append(dnsNames, "webhook.example.com"), labels, certs.RenewAt)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(renamed.Hash).NotTo(o.Equal(rotated.Hash))
+	g.Expect(parseCertificate(g, getSecret(g, c).Data[corev1.TLSCertKey]).DNSNames).To(o.ContainElement("webhook.example.com"))
+
+	// invalid certificates, e.g. from another issuer, are replaced
+	secret = getSecret(g, c)
+	secret.Data[CACertKey] = []byte("invalid")
+	g.Expect(c.Update(ctx, secret)).To(o.Succeed())
+	replaced, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, labels, now)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(replaced.CABundle).NotTo(o.Equal(certs.CABundle))
+	secret = getSecret(g, c)
+	g.Expect(parseCertificate(g, secret.Data[corev1.TLSCertKey]).CheckSignatureFrom(parseCertificate(g, secret.Data[CACertKey]))).To(o.Succeed())
+}
+
+func TestReconcileBuiltInCARotation(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(s)).To(o.Succeed())
+	g.Expect(appsv1.AddToScheme(s)).To(o.Succeed())
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	dnsNames := WebhookDNSNames("shipwright-build")
+	now := time.Now()
+
+	certs, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, nil, now)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	webhook := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shipwright-build", Name: common.BuildWebhookDeployment},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{CertificateHashAnnotation: certs.Hash}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	g.Expect(c.Create(ctx, webhook)).To(o.Succeed())
+
+	// the previous CA stays in the bundle while the webhook serves its certificate
+	rotateAt := renewAt(parseCertificate(g, certs.CABundle))
+	rotated, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, nil, rotateAt)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	secret := getSecret(g, c)
+	g.Expect(secret.Data[CACertKey]).NotTo(o.Equal(certs.CABundle))
+	g.Expect(secret.Data).To(o.HaveKeyWithValue(previousCACertKey, certs.CABundle))
+	g.Expect(rotated.CABundle).To(o.Equal(append(append([]byte{}, secret.Data[CACertKey]...), certs.CABundle...)))
+
+	// and while the webhook is rolled out with the new certificate
+	webhook.Spec.Template.Annotations[CertificateHashAnnotation] = rotated.Hash
+	g.Expect(c.Update(ctx, webhook)).To(o.Succeed())
+	webhook.Status.UpdatedReplicas = 0
+	g.Expect(c.Status().Update(ctx, webhook)).To(o.Succeed())
+	rollingOut, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, nil, rotateAt.Add(time.Minute))
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(rollingOut).To(o.Equal(rotated))
+
+	// the previous CA is dropped once the webhook is rolled out
+	webhook.Status.UpdatedReplicas = 1
+	g.Expect(c.Status().Update(ctx, webhook)).To(o.Succeed())
+	rolledOut, err := ReconcileBuiltIn(ctx, c, c, "shipwright-build", dnsNames, nil, rotateAt.Add(time.Minute))
+	g.Expect(err).NotTo(o.HaveOccurred())
+	secret = getSecret(g, c)
+	g.Expect(secret.Data).NotTo(o.HaveKey(previousCACertKey))
+	g.Expect(rolledOut.CABundle).To(o.Equal(secret.Data[CACertKey]))
+	g.Expect(rolledOut.Hash).To(o.Equal(rotated.Hash))
+}
+
+func TestInjectCABundle(t *testing.T) {
+	g := o.NewWithT(t)
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "builds.shipwright.io"},
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{"name": "shp-build-webhook"},
+					},
+				},
+			},
+		},
+	}}
+	withoutWebhook := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "clusterbuildstrategies.shipwright.io"},
+		"spec":       map[string]interface{}{},
+	}}
+
+	transformer := InjectCABundle([]byte("ca"))
+	g.Expect(transformer(crd)).To(o.Succeed())
+	g.Expect(transformer(withoutWebhook)).To(o.Succeed())
+	caBundle, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	g.Expect(caBundle).To(o.Equal(base64.StdEncoding.EncodeToString([]byte("ca"))))
+	_, found, _ := unstructured.NestedFieldNoCopy(withoutWebhook.Object, "spec", "conversion")
+	g.Expect(found).To(o.BeFalse())
+}
+
+func TestInjectCertificateHash(t *testing.T) {
+	g := o.NewWithT(t)
+	deployment := func(name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("apps/v1")
+		u.SetKind("Deployment")
+		u.SetName(name)
+		g.Expect(unstructured.SetNestedStringMap(u.Object, map[string]string{"existing": "value"},
+			"spec", "template", "metadata", "annotations")).To(o.Succeed())
+		return u
+	}
+	webhook := deployment("shipwright-build-webhook")
+	controller := deployment("shipwright-build-controller")
+
+	transformer := InjectCertificateHash("shipwright-build-webhook", "abc")
+	g.Expect(transformer(webhook)).To(o.Succeed())
+	g.Expect(transformer(controller)).To(o.Succeed())
+	annotations, _, _ := unstructured.NestedStringMap(webhook.Object, "spec", "template", "metadata", "annotations")
+	g.Expect(annotations).To(o.Equal(map[string]string{"existing": "value", CertificateHashAnnotation: "abc"}))
+	annotations, _, _ = unstructured.NestedStringMap(controller.Object, "spec", "template", "metadata", "annotations")
+	g.Expect(annotations).NotTo(o.HaveKey(CertificateHashAnnotation))
+}
@@ -0,0 +1,78 @@
+package certificates
+
+import (
+	"context"
+	"time"
+
+	mf "github.com/manifestival/manifestival"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ServingCertSecretAnnotation asks the OpenShift service CA to issue a serving certificate
+	// for the annotated Service, in the named Secret.
+	ServingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// InjectCABundleAnnotation asks the OpenShift service CA to inject its CA bundle in the
+	// annotated CRD.
+	InjectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+	// serviceCAExpiryAnnotation is set by the OpenShift service CA on the Secret, with the expiry
+	// of the serving certificate.
+	serviceCAExpiryAnnotation = "service.beta.openshift.io/expiry"
+)
+
+// ServiceCA describes the serving certificate issued by the OpenShift service CA.
+type ServiceCA struct {
+	// Ready tells whether the serving certificate is issued.
+	Ready bool
+	// NotAfter is the expiry of the serving certificate, when known.
+	NotAfter *metav1.Time
+}
+
+// InjectServiceCA annotates the webhook Service for the OpenShift service CA to issue its serving
+// certificate in the webhook Secret, and the CRDs for their conversion webhook to trust it.
+func InjectServiceCA() mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		switch {
+		case u.GetKind() == "Service" && u.GetName() == WebhookService:
+			setAnnotation(u, ServingCertSecretAnnotation, WebhookSecret)
+		case u.GetKind() == "CustomResourceDefinition":
+			setAnnotation(u, InjectCABundleAnnotation, "true")
+		}
+		return nil
+	}
+}
+
+// GetServiceCA returns the serving certificate issued by the OpenShift service CA in the webhook
+// Secret of the namespace. The Secret is read with the given reader, which should not be cached.
+func GetServiceCA(ctx context.Context, reader client.Reader, namespace string) (*ServiceCA, error) {
+	secret := &corev1.Secret{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: WebhookSecret}, secret)
+	if errors.IsNotFound(err) {
+		return &ServiceCA{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceCA := &ServiceCA{Ready: len(secret.Data[corev1.TLSCertKey]) > 0}
+	if expiry, ok := secret.Annotations[serviceCAExpiryAnnotation]; ok {
+		if notAfter, err := time.Parse(time.RFC3339, expiry); err == nil {
+			serviceCA.NotAfter = &metav1.Time{Time: notAfter}
+		}
+	}
+	return serviceCA, nil
+}
+
+func setAnnotation(u *unstructured.Unstructured, key, value string) {
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	u.SetAnnotations(annotations)
+}
@@ -0,0 +1,64 @@
+package certificates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	o "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInjectServiceCA(t *testing.T) {
+	g := o.NewWithT(t)
+	resource := func(kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetKind(kind)
+		u.SetName(name)
+		return u
+	}
+	service := resource("Service", "shp-build-webhook")
+	otherService := resource("Service", "shipwright-triggers")
+	crd := resource("CustomResourceDefinition", "builds.shipwright.io")
+	deployment := resource("Deployment", "shipwright-build-webhook")
+
+	transformer := InjectServiceCA()
+	for _, u := range []*unstructured.Unstructured{service, otherService, crd, deployment} {
+		g.Expect(transformer(u)).To(o.Succeed())
+	}
+	g.Expect(service.GetAnnotations()).To(o.Equal(map[string]string{ServingCertSecretAnnotation: "shipwright-build-webhook-cert"}))
+	g.Expect(crd.GetAnnotations()).To(o.Equal(map[string]string{InjectCABundleAnnotation: "true"}))
+	g.Expect(otherService.GetAnnotations()).To(o.BeEmpty())
+	g.Expect(deployment.GetAnnotations()).To(o.BeEmpty())
+}
+
+func TestGetServiceCA(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(s)).To(o.Succeed())
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+
+	// the certificate is not issued until the Secret is created
+	serviceCA, err := GetServiceCA(ctx, c, "shipwright-build")
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(serviceCA).To(o.Equal(&ServiceCA{}))
+
+	g.Expect(c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "shipwright-build",
+			Name:        WebhookSecret,
+			Annotations: map[string]string{serviceCAExpiryAnnotation: "2028-10-18T00:00:00Z"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	})).To(o.Succeed())
+	serviceCA, err = GetServiceCA(ctx, c, "shipwright-build")
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(serviceCA.Ready).To(o.BeTrue())
+	g.Expect(serviceCA.NotAfter.Time).To(o.Equal(time.Date(2028, 10, 18, 0, 0, 0, 0, time.UTC)))
+}
@@ -3,23 +3,48 @@ package certmanager
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/go-logr/logr"
 	mf "github.com/manifestival/manifestival"
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/common"
 	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// CertificateName is the name of the webhook Certificate, and of the Secret it is issued to.
+	CertificateName = "shipwright-build-webhook-cert"
+	// selfSignedIssuerName is the name of the Issuer created when no issuer is referenced.
+	selfSignedIssuerName = "selfsigned-issuer"
+)
+
 var (
 	certDomainsTemplate = []string{
 		"shp-build-webhook.%s.svc",
 	}
 )
 
-func ReconcileCertManager(ctx context.Context, crdClient crdclientv1.ApiextensionsV1Interface, client client.Client, logger logr.Logger, namespace string) (bool, error) {
+// CertificateStatus describes the webhook Certificate issued by cert-manager.
+type CertificateStatus struct {
+	// Ready tells whether the Certificate is issued and valid.
+	Ready bool
+	// Message explains the Ready condition of the Certificate.
+	Message string
+	// NotAfter is the expiry of the issued certificate.
+	NotAfter *metav1.Time
+	// RenewalTime is the time at which cert-manager renews the certificate.
+	RenewalTime *metav1.Time
+}
+
+// ReconcileCertManager applies the webhook Certificate, issued by the Issuer or ClusterIssuer
+// referenced in the spec, or by a self-signed Issuer created in the namespace otherwise.
+func ReconcileCertManager(ctx context.Context, crdClient crdclientv1.ApiextensionsV1Interface, client client.Client, logger logr.Logger, namespace string, spec *v1alpha1.CertificatesSpec) (bool, error) {
 	certificatesInstalled, err := isCertificatesInstalled(ctx, crdClient)
 	if err != nil {
 		return true, err
@@ -34,23 +59,113 @@ func ReconcileCertManager(ctx context.Context, crdClient crdclientv1.Apiextensio
 		}
 	}
 
+	manifest, err := certificatesManifest(client, logger, namespace, spec)
+	if err != nil {
+		return true, err
+	}
+
+	// the self-signed Issuer is only used when no issuer is referenced
+	if spec != nil && spec.IssuerRef != nil {
+		if err = manifest.Filter(mf.ByKind("Issuer")).Delete(); err != nil {
+			return true, err
+		}
+		manifest = manifest.Filter(mf.Not(mf.ByKind("Issuer")))
+	}
+
+	if err = manifest.Apply(); err != nil {
+		return true, err
+	}
+
+	return false, nil
+}
+
+// Manifest returns the resources applied by ReconcileCertManager: the webhook Certificate, and
+// the self-signed Issuer when no issuer is referenced.
+func Manifest(client client.Client, logger logr.Logger, namespace string, spec *v1alpha1.CertificatesSpec) (mf.Manifest, error) {
+	manifest, err := certificatesManifest(client, logger, namespace, spec)
+	if err != nil {
+		return mf.Manifest{}, err
+	}
+	if spec != nil && spec.IssuerRef != nil {
+		manifest = manifest.Filter(mf.Not(mf.ByKind("Issuer")))
+	}
+	return manifest, nil
+}
+
+// certificatesManifest renders the embedded certificates manifest for the namespace and spec.
+func certificatesManifest(client client.Client, logger logr.Logger, namespace string, spec *v1alpha1.CertificatesSpec) (mf.Manifest, error) {
 	manifest, err := common.SetupManifestival(client, "certificates.yaml", false, logger)
 	if err != nil {
-		return true, fmt.Errorf("error creating inital certificates manifest")
+		return mf.Manifest{}, fmt.Errorf("error creating inital certificates manifest")
 	}
 	manifest, err = manifest.
 		Filter(mf.Not(mf.ByKind("Namespace"))).
-		Transform(mf.InjectNamespace(namespace), injectDnsNames(buildCertDomains(namespace)))
+		Transform(mf.InjectNamespace(namespace), injectDnsNames(buildCertDomains(namespace, spec)), injectCertificateSpec(spec))
+	if err != nil {
+		return mf.Manifest{}, fmt.Errorf("error transorming manifest using target namespace: %w", err)
+	}
+	return manifest, nil
+}
+
+// GetCertificateStatus returns the status of the webhook Certificate in the namespace.
+func GetCertificateStatus(ctx context.Context, reader client.Reader, namespace string) (*CertificateStatus, error) {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: CertificateName}, certificate); err != nil {
+		return nil, err
+	}
 
+	status := &CertificateStatus{Message: "Waiting for the certificate to be issued"}
+	conditions, _, err := unstructured.NestedSlice(certificate.Object, "status", "conditions")
 	if err != nil {
-		return true, fmt.Errorf("error transorming manifest using target namespace")
+		return nil, err
+	}
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		status.Ready = condition["status"] == "True"
+		if message, ok := condition["message"].(string); ok && message != "" {
+			status.Message = message
+		}
+	}
+	if status.NotAfter, err = nestedTime(certificate, "status", "notAfter"); err != nil {
+		return nil, err
 	}
+	if status.RenewalTime, err = nestedTime(certificate, "status", "renewalTime"); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
 
-	if err = manifest.Apply(); err != nil {
-		return true, err
+// IssuerName returns the issuer of the webhook Certificate, in the "<kind>/<name>" form.
+func IssuerName(spec *v1alpha1.CertificatesSpec) string {
+	if spec == nil || spec.IssuerRef == nil {
+		return fmt.Sprintf("%s/%s", v1alpha1.IssuerKindIssuer, selfSignedIssuerName)
 	}
+	return fmt.Sprintf("%s/%s", issuerKind(spec.IssuerRef), spec.IssuerRef.Name)
+}
 
-	return false, nil
+func issuerKind(ref *v1alpha1.IssuerReference) v1alpha1.IssuerKind {
+	if ref.Kind == "" {
+		return v1alpha1.IssuerKindIssuer
+	}
+	return ref.Kind
+}
+
+// nestedTime parses the RFC3339 timestamp of the given field, returning nil when it is not set.
+func nestedTime(u *unstructured.Unstructured, fields ...string) (*metav1.Time, error) {
+	value, found, err := unstructured.NestedString(u.Object, fields...)
+	if err != nil || !found || value == "" {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.Time{Time: t}, nil
 }
 
 func isCertificatesInstalled(ctx context.Context, client crdclientv1.ApiextensionsV1Interface) (bool, error) {
@@ -83,11 +198,60 @@ func injectDnsNames(domains []string) mf.Transformer {
 	}
 }
 
-// buildCertDomains injects namespace and returns a slice of svc dnsdomains
-func buildCertDomains(targetNamespace string) []string {
+// injectCertificateSpec sets the issuer, lifetime and private key of the spec on the Certificate.
+func injectCertificateSpec(spec *v1alpha1.CertificatesSpec) mf.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "Certificate" || spec == nil {
+			return nil
+		}
+
+		if ref := spec.IssuerRef; ref != nil {
+			issuerRef := map[string]interface{}{
+				"name": ref.Name,
+				"kind": string(issuerKind(ref)),
+			}
+			if ref.Group != "" {
+				issuerRef["group"] = ref.Group
+			}
+			if err := unstructured.SetNestedMap(u.Object, issuerRef, "spec", "issuerRef"); err != nil {
+				return err
+			}
+		}
+		if spec.Duration != nil {
+			if err := unstructured.SetNestedField(u.Object, spec.Duration.Duration.String(), "spec", "duration"); err != nil {
+				return err
+			}
+		}
+		if spec.RenewBefore != nil {
+			if err := unstructured.SetNestedField(u.Object, spec.RenewBefore.Duration.String(), "spec", "renewBefore"); err != nil {
+				return err
+			}
+		}
+		if key := spec.PrivateKey; key != nil {
+			privateKey := map[string]interface{}{}
+			if key.Algorithm != "" {
+				privateKey["algorithm"] = string(key.Algorithm)
+			}
+			if key.Size > 0 {
+				privateKey["size"] = int64(key.Size)
+			}
+			if err := unstructured.SetNestedMap(u.Object, privateKey, "spec", "privateKey"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// buildCertDomains injects namespace and returns a slice of svc dnsdomains, followed by the
+// additional DNS names of the spec.
+func buildCertDomains(targetNamespace string, spec *v1alpha1.CertificatesSpec) []string {
 	domains := []string{}
 	for _, t := range certDomainsTemplate {
 		domains = append(domains, fmt.Sprintf(t, targetNamespace))
 	}
+	if spec != nil {
+		domains = append(domains, spec.DNSNames...)
+	}
 	return domains
 }
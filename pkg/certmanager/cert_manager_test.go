@@ -3,12 +3,17 @@ package certmanager
 import (
 	"context"
 	"testing"
+	"time"
 
 	o "github.com/onsi/gomega"
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -59,7 +64,7 @@ func TestReconcileCertManager(t *testing.T) {
 			}
 			crdClient := apiextensionsfake.NewSimpleClientset(crds...)
 			c := fake.NewClientBuilder().Build()
-			requeue, err := ReconcileCertManager(ctx, crdClient.ApiextensionsV1(), c, zap.New(), "shipwright-build")
+			requeue, err := ReconcileCertManager(ctx, crdClient.ApiextensionsV1(), c, zap.New(), "shipwright-build", nil)
 			if tc.expectError {
 				g.Expect(err).To(o.HaveOccurred())
 			} else {
@@ -70,3 +75,104 @@ func TestReconcileCertManager(t *testing.T) {
 	}
 
 }
+
+func TestReconcileCertManagerSpec(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+	crdClient := apiextensionsfake.NewSimpleClientset(&apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "certificates.cert-manager.io"},
+	})
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Issuer",
+		"metadata":   map[string]interface{}{"namespace": "shipwright-build", "name": "selfsigned-issuer"},
+		"spec":       map[string]interface{}{"selfSigned": map[string]interface{}{}},
+	}}
+	c := fake.NewClientBuilder().WithObjects(issuer).Build()
+
+	spec := &v1alpha1.CertificatesSpec{
+		IssuerRef:   &v1alpha1.IssuerReference{Name: "corporate-ca", Kind: v1alpha1.IssuerKindClusterIssuer},
+		Duration:    &metav1.Duration{Duration: 720 * time.Hour},
+		RenewBefore: &metav1.Duration{Duration: 240 * time.Hour},
+		PrivateKey:  &v1alpha1.PrivateKeySpec{Algorithm: v1alpha1.PrivateKeyAlgorithmECDSA, Size: 384},
+		DNSNames:    []string{"webhook.example.com"},
+	}
+	_, err := ReconcileCertManager(ctx, crdClient.ApiextensionsV1(), c, zap.New(), "shipwright-build", spec)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	err = c.Get(ctx, types.NamespacedName{Namespace: "shipwright-build", Name: "selfsigned-issuer"}, issuer)
+	g.Expect(errors.IsNotFound(err)).To(o.BeTrue(), "the self-signed issuer should be removed when an issuer is referenced")
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	err = c.Get(ctx, types.NamespacedName{Namespace: "shipwright-build", Name: CertificateName}, certificate)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	certificateSpec, _, _ := unstructured.NestedMap(certificate.Object, "spec")
+	g.Expect(certificateSpec).To(o.Equal(map[string]interface{}{
+		"dnsNames":    []interface{}{"shp-build-webhook.shipwright-build.svc", "webhook.example.com"},
+		"issuerRef":   map[string]interface{}{"name": "corporate-ca", "kind": "ClusterIssuer"},
+		"secretName":  CertificateName,
+		"duration":    "720h0m0s",
+		"renewBefore": "240h0m0s",
+		"privateKey":  map[string]interface{}{"algorithm": "ECDSA", "size": int64(384)},
+	}))
+	g.Expect(IssuerName(spec)).To(o.Equal("ClusterIssuer/corporate-ca"))
+	g.Expect(IssuerName(nil)).To(o.Equal("Issuer/selfsigned-issuer"))
+
+	// invalid DNS names are rejected
+	spec.DNSNames = []string{"not a dns name"}
+	_, err = ReconcileCertManager(ctx, crdClient.ApiextensionsV1(), c, zap.New(), "shipwright-build", spec)
+	g.Expect(err).To(o.HaveOccurred())
+}
+
+func TestManifest(t *testing.T) {
+	g := o.NewWithT(t)
+	c := fake.NewClientBuilder().Build()
+	kinds := func(spec *v1alpha1.CertificatesSpec) []string {
+		manifest, err := Manifest(c, zap.New(), "shipwright-build", spec)
+		g.Expect(err).NotTo(o.HaveOccurred())
+		names := []string{}
+		for _, u := range manifest.Resources() {
+			g.Expect(u.GetNamespace()).To(o.Equal("shipwright-build"))
+			names = append(names, u.GetKind())
+		}
+		return names
+	}
+
+	g.Expect(kinds(nil)).To(o.ConsistOf("Issuer", "Certificate"))
+	g.Expect(kinds(&v1alpha1.CertificatesSpec{
+		IssuerRef: &v1alpha1.IssuerReference{Name: "corporate-ca"},
+	})).To(o.ConsistOf("Certificate"))
+}
+
+func TestGetCertificateStatus(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"namespace": "shipwright-build", "name": CertificateName},
+	}}
+	c := fake.NewClientBuilder().WithObjects(certificate).Build()
+
+	status, err := GetCertificateStatus(ctx, c, "shipwright-build")
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(status).To(o.Equal(&CertificateStatus{Message: "Waiting for the certificate to be issued"}))
+
+	g.Expect(unstructured.SetNestedField(certificate.Object, map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{
+			"type":    "Ready",
+			"status":  "True",
+			"message": "Certificate is up to date and has not expired",
+		}},
+		"notAfter":    "2027-01-01T00:00:00Z",
+		"renewalTime": "2026-12-01T00:00:00Z",
+	}, "status")).To(o.Succeed())
+	g.Expect(c.Update(ctx, certificate)).To(o.Succeed())
+	status, err = GetCertificateStatus(ctx, c, "shipwright-build")
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(status.Ready).To(o.BeTrue())
+	g.Expect(status.Message).To(o.Equal("Certificate is up to date and has not expired"))
+	g.Expect(status.NotAfter.Time).To(o.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)))
+	g.Expect(status.RenewalTime.Time).To(o.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)))
+}
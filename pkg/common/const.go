@@ -5,8 +5,6 @@ const (
 	ShipwrightImagePrefix = "IMAGE_SHIPWRIGHT_"
 
 	TektonOpMinSupportedVersion = "v0.50.0"
-	TektonOpMinSupportedMajor   = 0
-	TektonOpMinSupportedMinor   = 50
 
 	Retain int = iota
 	Overwrite
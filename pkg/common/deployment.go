@@ -0,0 +1,155 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+const (
+	// BuildControllerDeployment is the name of the Shipwright Build controller Deployment.
+	BuildControllerDeployment = "shipwright-build-controller"
+	// BuildWebhookDeployment is the name of the Shipwright Build webhook Deployment.
+	BuildWebhookDeployment = "shipwright-build-webhook"
+	// TriggersDeployment is the name of the Shipwright Triggers Deployment.
+	TriggersDeployment = "shipwright-triggers"
+)
+
+// ComponentDeploymentOverrides maps the per-component deployment overrides of the given spec to
+// the name of the Deployment they apply to. Components without overrides are omitted.
+func ComponentDeploymentOverrides(spec *v1alpha1.ShipwrightBuildSpec) map[string]*v1alpha1.DeploymentOverride {
+	overrides := map[string]*v1alpha1.DeploymentOverride{}
+	if spec.Build != nil {
+		if spec.Build.Controller != nil {
+			overrides[BuildControllerDeployment] = spec.Build.Controller
+		}
+		if spec.Build.Webhook != nil {
+			overrides[BuildWebhookDeployment] = spec.Build.Webhook
+		}
+	}
+	if spec.Triggers != nil {
+		overrides[TriggersDeployment] = &spec.Triggers.DeploymentOverride
+	}
+	return overrides
+}
+
+// DeploymentOverrides merges the deployment overrides, keyed by Deployment name, into the
+// matching Deployments.
+func DeploymentOverrides(overrides map[string]*v1alpha1.DeploymentOverride) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "Deployment" {
+			return nil
+		}
+		override, exists := overrides[u.GetName()]
+		if !exists || override == nil {
+			return nil
+		}
+
+		d := &appsv1.Deployment{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)
+		if err != nil {
+			return err
+		}
+
+		applyDeploymentOverride(d, override)
+		unstrObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+		if err != nil {
+			return err
+		}
+		u.SetUnstructuredContent(unstrObj)
+
+		return nil
+	}
+}
+
+func applyDeploymentOverride(d *appsv1.Deployment, override *v1alpha1.DeploymentOverride) {
+	if override.Replicas != nil {
+		replicas := *override.Replicas
+		d.Spec.Replicas = &replicas
+	}
+
+	podSpec := &d.Spec.Template.Spec
+	if override.Resources != nil {
+		for i := range podSpec.Containers {
+			mergeResources(&podSpec.Containers[i].Resources, override.Resources)
+		}
+	}
+	if len(override.NodeSelector) > 0 {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = map[string]string{}
+		}
+		for k, v := range override.NodeSelector {
+			podSpec.NodeSelector[k] = v
+		}
+	}
+	if len(override.Tolerations) > 0 {
+		podSpec.Tolerations = override.Tolerations
+	}
+	if override.Affinity != nil {
+		podSpec.Affinity = override.Affinity
+	}
+	if len(override.TopologySpreadConstraints) > 0 {
+		podSpec.TopologySpreadConstraints = override.TopologySpreadConstraints
+	}
+	if override.PriorityClassName != "" {
+		podSpec.PriorityClassName = override.PriorityClassName
+	}
+}
+
+// mergeResources sets every request and limit found in override on the given container resources,
+// keeping the ones that are not overridden.
+func mergeResources(resources *corev1.ResourceRequirements, override *corev1.ResourceRequirements) {
+	if len(override.Requests) > 0 {
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		for name, quantity := range override.Requests {
+			resources.Requests[name] = quantity.DeepCopy()
+		}
+	}
+	if len(override.Limits) > 0 {
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		for name, quantity := range override.Limits {
+			resources.Limits[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// DeploymentVersion returns the version of the first container image of the named Deployment, or
+// an empty string when the Deployment is not part of the given resources.
+func DeploymentVersion(resources []unstructured.Unstructured, name string) string {
+	for _, obj := range resources {
+		if obj.GetKind() != "Deployment" || obj.GetName() != name {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		if len(containers) == 0 {
+			return ""
+		}
+		container, ok := containers[0].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		image, _, _ := unstructured.NestedString(container, "image")
+		return ImageVersion(image)
+	}
+	return ""
+}
+
+// ImageVersion returns the tag of the given image reference, or its digest when it is not
+// tagged.
+func ImageVersion(image string) string {
+	name, digest, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[i+1:]
+	}
+	return digest
+}
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"path"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func TestComponentDeploymentOverrides(t *testing.T) {
+	controller := &v1alpha1.DeploymentOverride{PriorityClassName: "controller"}
+	webhook := &v1alpha1.DeploymentOverride{PriorityClassName: "webhook"}
+
+	cases := []struct {
+		name     string
+		spec     v1alpha1.ShipwrightBuildSpec
+		expected map[string]string
+	}{
+		{
+			name:     "no overrides",
+			spec:     v1alpha1.ShipwrightBuildSpec{},
+			expected: map[string]string{},
+		},
+		{
+			name: "build components",
+			spec: v1alpha1.ShipwrightBuildSpec{
+				Build: &v1alpha1.BuildSpec{
+					Controller: controller,
+					Webhook:    webhook,
+				},
+			},
+			expected: map[string]string{
+				BuildControllerDeployment: "controller",
+				BuildWebhookDeployment:    "webhook",
+			},
+		},
+		{
+			name: "triggers component",
+			spec: v1alpha1.ShipwrightBuildSpec{
+				Triggers: &v1alpha1.TriggersSpec{
+					Deployment:         v1alpha1.TriggersDeploymentEnabled,
+					DeploymentOverride: v1alpha1.DeploymentOverride{PriorityClassName: "triggers"},
+				},
+			},
+			expected: map[string]string{
+				TriggersDeployment: "triggers",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			overrides := ComponentDeploymentOverrides(&tc.spec)
+			names := map[string]string{}
+			for deployment, override := range overrides {
+				names[deployment] = override.PriorityClassName
+			}
+			g.Expect(names).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestDeploymentOverrides(t *testing.T) {
+	replicas := int32(3)
+	overrides := map[string]*v1alpha1.DeploymentOverride{
+		BuildControllerDeployment: {
+			Replicas: &replicas,
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			},
+			NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			Tolerations: []corev1.Toleration{{
+				Key:      "node-role.kubernetes.io/infra",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}},
+			PriorityClassName: "system-cluster-critical",
+		},
+	}
+
+	g := NewWithT(t)
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-deployment-overrides.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	newManifest, err := manifest.Transform(DeploymentOverrides(overrides))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	t.Run("merge overrides into the matching deployment", func(t *testing.T) {
+		g := NewWithT(t)
+		d := deploymentByName(t, newManifest.Resources(), BuildControllerDeployment)
+		g.Expect(*d.Spec.Replicas).To(Equal(replicas))
+
+		podSpec := d.Spec.Template.Spec
+		g.Expect(podSpec.NodeSelector).To(Equal(map[string]string{
+			"kubernetes.io/os":              "linux",
+			"node-role.kubernetes.io/infra": "",
+		}))
+		g.Expect(podSpec.Tolerations).To(HaveLen(1))
+		g.Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+		g.Expect(podSpec.PriorityClassName).To(Equal("system-cluster-critical"))
+
+		resources := podSpec.Containers[0].Resources
+		g.Expect(resources.Requests.Cpu().String()).To(Equal("100m"))
+		g.Expect(resources.Requests.Memory().String()).To(Equal("256Mi"))
+		g.Expect(resources.Limits.Memory().String()).To(Equal("512Mi"))
+	})
+
+	t.Run("leave other deployments untouched", func(t *testing.T) {
+		g := NewWithT(t)
+		d := deploymentByName(t, newManifest.Resources(), BuildWebhookDeployment)
+		g.Expect(*d.Spec.Replicas).To(Equal(int32(1)))
+		g.Expect(d.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+		g.Expect(d.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+	})
+}
+
+func deploymentByName(t *testing.T, resources []unstructured.Unstructured, name string) *appsv1.Deployment {
+	t.Helper()
+
+	for _, obj := range resources {
+		if obj.GetKind() == "Deployment" && obj.GetName() == name {
+			return deploymentFor(t, obj)
+		}
+	}
+	t.Fatalf("deployment %s not found", name)
+	return nil
+}
+
+func TestImageVersion(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected string
+	}{
+		{
+			image:    "ghcr.io/shipwright-io/build/shipwright-build-controller:v0.20.0@sha256:a2a4d7d20d96e9804e862fd24d9d2b7a726cc9bdce43b1facb65576c03dd481c",
+			expected: "v0.20.0",
+		},
+		{
+			image:    "ghcr.io/shipwright-io/triggers/triggers@sha256:fed8989bcb2ca7b8986659be74c16463858884f031789eb905e5c519dae1523d",
+			expected: "sha256:fed8989bcb2ca7b8986659be74c16463858884f031789eb905e5c519dae1523d",
+		},
+		{
+			image:    "registry.example.com:5000/shipwright/build",
+			expected: "",
+		},
+		{
+			image:    "registry.example.com:5000/shipwright/build:nightly",
+			expected: "nightly",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(ImageVersion(tc.image)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestDeploymentVersion(t *testing.T) {
+	g := NewWithT(t)
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-deployment-overrides.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(DeploymentVersion(manifest.Resources(), BuildControllerDeployment)).To(Equal("1.36"))
+	g.Expect(DeploymentVersion(manifest.Resources(), TriggersDeployment)).To(BeEmpty())
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+// ImageMirrors rewrites the image references of Deployment containers, init containers, image env
+// vars and ClusterBuildStrategy steps according to the given mirrors.
+func ImageMirrors(mirrors []v1alpha1.ImageMirror) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if len(mirrors) == 0 {
+			return nil
+		}
+		switch u.GetKind() {
+		case "Deployment":
+			d := &appsv1.Deployment{}
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)
+			if err != nil {
+				return err
+			}
+
+			mirrorContainerImages(d.Spec.Template.Spec.InitContainers, mirrors)
+			mirrorContainerImages(d.Spec.Template.Spec.Containers, mirrors)
+			unstrObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+			if err != nil {
+				return err
+			}
+			u.SetUnstructuredContent(unstrObj)
+		case "ClusterBuildStrategy":
+			return transformStrategySteps(u, func(step map[string]interface{}) error {
+				if image, _, _ := unstructured.NestedString(step, "image"); image != "" {
+					step["image"] = MirrorImage(image, mirrors)
+				}
+				return nil
+			})
+		}
+		return nil
+	}
+}
+
+const (
+	// dockerHubDomain is the registry of the image references without a domain.
+	dockerHubDomain = "docker.io"
+	// legacyDockerHubDomain is the former domain of Docker Hub, normalized to dockerHubDomain.
+	legacyDockerHubDomain = "index.docker.io"
+	// officialRepositoryPrefix is the namespace of the Docker Hub images without a namespace.
+	officialRepositoryPrefix = "library/"
+)
+
+// MirrorImage returns the image reference rewritten with the mirror whose source is the longest
+// prefix of the image. The image and the sources are normalized the way container runtimes resolve
+// them, so "busybox", "docker.io/busybox" and "docker.io/library/busybox" all match the
+// "docker.io/library" source. Sources only match on a repository boundary, so "quay.io/foo"
+// matches "quay.io/foo/bar" but not "quay.io/foobar", and a source naming a repository also
+// matches its tags and digests. The image is returned as-is when no mirror matches.
+func MirrorImage(image string, mirrors []v1alpha1.ImageMirror) string {
+	normalized := normalizeImage(image)
+	var mirror, source string
+	for _, m := range mirrors {
+		s := normalizeMirrorSource(m.Source)
+		if s == "" || len(s) <= len(source) || !strings.HasPrefix(normalized, s) {
+			continue
+		}
+		if rest := normalized[len(s):]; rest != "" && !repositoryBoundary(s, rest[0]) {
+			continue
+		}
+		mirror, source = m.Mirror, s
+	}
+	if source == "" {
+		return image
+	}
+	return strings.TrimSuffix(mirror, "/") + normalized[len(source):]
+}
+
+// repositoryBoundary returns true when the character following a source in an image reference
+// ends a path segment of the source. Tags and digests only follow a repository, not a registry
+// domain, whose port would be mistaken for a tag otherwise.
+func repositoryBoundary(source string, next byte) bool {
+	switch next {
+	case '/':
+		return true
+	case ':', '@':
+		return strings.Contains(source, "/")
+	}
+	return false
+}
+
+// normalizeImage returns the fully qualified form of the image reference: the references without
+// a registry domain are Docker Hub images, and the Docker Hub images without a namespace are
+// official images.
+func normalizeImage(image string) string {
+	domain, remainder := dockerHubDomain, image
+	if i := strings.IndexRune(image, '/'); i != -1 &&
+		(strings.ContainsAny(image[:i], ".:") || image[:i] == "localhost" || strings.ToLower(image[:i]) != image[:i]) {
+		domain, remainder = image[:i], image[i+1:]
+	}
+	if domain == legacyDockerHubDomain {
+		domain = dockerHubDomain
+	}
+	if domain == dockerHubDomain && !strings.ContainsRune(remainder, '/') {
+		remainder = officialRepositoryPrefix + remainder
+	}
+	return domain + "/" + remainder
+}
+
+// normalizeMirrorSource returns the fully qualified form of a mirror source, which is either a
+// registry domain, such as "docker.io", the namespace of the official Docker Hub images, or a
+// repository prefix normalized as an image.
+func normalizeMirrorSource(source string) string {
+	source = strings.TrimSuffix(source, "/")
+	official := strings.TrimSuffix(officialRepositoryPrefix, "/")
+	switch {
+	case source == "":
+		return ""
+	case source == legacyDockerHubDomain:
+		return dockerHubDomain
+	case source == dockerHubDomain+"/"+official || source == legacyDockerHubDomain+"/"+official:
+		return dockerHubDomain + "/" + official
+	case !strings.Contains(source, "/") && (strings.ContainsAny(source, ".:") || source == "localhost"):
+		return source
+	}
+	return normalizeImage(source)
+}
+
+func mirrorContainerImages(containers []corev1.Container, mirrors []v1alpha1.ImageMirror) {
+	for i, container := range containers {
+		containers[i].Image = MirrorImage(container.Image, mirrors)
+		for j, env := range container.Env {
+			if strings.HasSuffix(env.Name, "_IMAGE") && env.Value != "" {
+				container.Env[j].Value = MirrorImage(env.Value, mirrors)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"path"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func TestMirrorImage(t *testing.T) {
+	mirrors := []v1alpha1.ImageMirror{
+		{Source: "ghcr.io/shipwright-io", Mirror: "registry.example.com/shipwright-io"},
+		{Source: "ghcr.io/shipwright-io/build/git", Mirror: "git-mirror.example.com/git"},
+		{Source: "docker.io/", Mirror: "registry.example.com/dockerhub/"},
+		{Source: "busybox", Mirror: "registry.example.com/library/busybox"},
+		{Source: "registry.local", Mirror: "registry.example.com/local"},
+	}
+
+	cases := []struct {
+		name     string
+		image    string
+		expected string
+	}{
+		{
+			name:     "repository prefix",
+			image:    "ghcr.io/shipwright-io/build/shipwright-build-controller:v0.20.0",
+			expected: "registry.example.com/shipwright-io/build/shipwright-build-controller:v0.20.0",
+		},
+		{
+			name:     "longest prefix wins",
+			image:    "ghcr.io/shipwright-io/build/git:v0.20.0@sha256:54767613da2d96a38c374a59394020d8c8c0f266af415681ef3adf62deb81de2",
+			expected: "git-mirror.example.com/git:v0.20.0@sha256:54767613da2d96a38c374a59394020d8c8c0f266af415681ef3adf62deb81de2",
+		},
+		{
+			name:     "trailing slashes are ignored",
+			image:    "docker.io/paketobuildpacks/builder-jammy-full:latest",
+			expected: "registry.example.com/dockerhub/paketobuildpacks/builder-jammy-full:latest",
+		},
+		{
+			name:     "exact image match",
+			image:    "busybox",
+			expected: "registry.example.com/library/busybox",
+		},
+		{
+			name:     "qualified official image",
+			image:    "docker.io/library/busybox:1.37",
+			expected: "registry.example.com/library/busybox:1.37",
+		},
+		{
+			name:     "legacy Docker Hub domain",
+			image:    "index.docker.io/library/busybox",
+			expected: "registry.example.com/library/busybox",
+		},
+		{
+			name:     "Docker Hub image without domain",
+			image:    "paketobuildpacks/builder-jammy-full:latest",
+			expected: "registry.example.com/dockerhub/paketobuildpacks/builder-jammy-full:latest",
+		},
+		{
+			name:     "official image without domain",
+			image:    "alpine:3.22",
+			expected: "registry.example.com/dockerhub/library/alpine:3.22",
+		},
+		{
+			name:     "partial repository names do not match",
+			image:    "busyboxes:latest",
+			expected: "registry.example.com/dockerhub/library/busyboxes:latest",
+		},
+		{
+			name:     "registry ports are not tags",
+			image:    "registry.local:5000/app:latest",
+			expected: "registry.local:5000/app:latest",
+		},
+		{
+			name:     "partial path segments do not match",
+			image:    "ghcr.io/shipwright-io-fork/build:latest",
+			expected: "ghcr.io/shipwright-io-fork/build:latest",
+		},
+		{
+			name:     "no matching mirror",
+			image:    "quay.io/containers/buildah:v1.43.1",
+			expected: "quay.io/containers/buildah:v1.43.1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(MirrorImage(tc.image, mirrors)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestImageMirrors(t *testing.T) {
+	g := NewWithT(t)
+	mirrors := []v1alpha1.ImageMirror{
+		{Source: "ghcr.io/shipwright-io", Mirror: "registry.example.com/shipwright-io"},
+		{Source: "docker.io", Mirror: "registry.example.com/dockerhub"},
+		{Source: "busybox", Mirror: "registry.example.com/busybox"},
+	}
+
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-image.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	strategies, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-strategy-image.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	manifest = manifest.Append(strategies)
+
+	newManifest, err := manifest.Transform(ImageMirrors(mirrors))
+	g.Expect(err).NotTo(HaveOccurred())
+	images, err := EffectiveImages(newManifest.Resources())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(images).To(Equal(map[string]string{
+		"shipwright_controller":               "registry.example.com/busybox",
+		"sidecar":                             "registry.example.com/busybox",
+		"image_shpwright_git_container_image": "registry.example.com/shipwright-io/build/git:v0.11.0@sha256:aecf8bdc01ea00be83e933162a0b6d063846b315fe9dcae60e4be1a34e85d514",
+		"prepare":                             "registry.example.com/busybox",
+		"controller":                          "registry.example.com/busybox",
+		"buildkit/build_and_push":             "registry.example.com/dockerhub/moby/buildkit:v0.30.0-rootless",
+		"buildkit/push":                       "registry.example.com/dockerhub/moby/buildkit:v0.30.0-rootless",
+	}))
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/manifestival/manifestival"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+// overrideScheme holds the kinds supporting strategic merge patches.
+var overrideScheme = newOverrideScheme()
+
+func newOverrideScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(apiextv1.AddToScheme(s))
+	return s
+}
+
+// Overrides applies the patches of the ShipwrightBuild overrides to the rendered resources. The
+// overrides which are invalid, or fail to patch a resource, are recorded instead of failing the
+// transformation, so that they can be reported on the status.
+type Overrides struct {
+	overrides []v1alpha1.ResourceOverride
+	patches   [][]byte // JSON patch of each override, nil when invalid
+	failed    []bool   // overrides which already reported an error
+	errs      []error
+}
+
+// NewOverrides parses the patches of the overrides, recording the errors of the invalid ones.
+func NewOverrides(overrides []v1alpha1.ResourceOverride) *Overrides {
+	o := &Overrides{
+		overrides: overrides,
+		patches:   make([][]byte, len(overrides)),
+		failed:    make([]bool, len(overrides)),
+	}
+	for i, override := range overrides {
+		patch, err := parsePatch(override)
+		if err != nil {
+			o.addError(i, err)
+			continue
+		}
+		o.patches[i] = patch
+	}
+	return o
+}
+
+// Transformer patches the resources matching the target of the valid overrides, in order. A
+// resource failing to be patched by an override is left as is by that override.
+func (o *Overrides) Transformer() manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		for i, override := range o.overrides {
+			if o.patches[i] == nil || !overrideTargets(override.Target, u) {
+				continue
+			}
+			patched, err := applyPatch(u, override.Type, o.patches[i])
+			if err != nil {
+				o.addError(i, fmt.Errorf("patching %s %q: %w", u.GetKind(), u.GetName(), err))
+				continue
+			}
+			u.SetUnstructuredContent(patched)
+		}
+		return nil
+	}
+}
+
+// Errors returns the errors of the overrides which are invalid or failed to apply, the first one
+// of each override.
+func (o *Overrides) Errors() []error {
+	return o.errs
+}
+
+func (o *Overrides) addError(i int, err error) {
+	if o.failed[i] {
+		return
+	}
+	o.failed[i] = true
+	o.errs = append(o.errs, fmt.Errorf("overrides[%d]: %w", i, err))
+}
+
+// overrideTargets checks whether the resource is selected by the target.
+func overrideTargets(target v1alpha1.OverrideTarget, u *unstructured.Unstructured) bool {
+	return u.GetKind() == target.Kind && (target.Name == "" || u.GetName() == target.Name)
+}
+
+// parsePatch converts the YAML or JSON patch of the override to JSON, and checks that it matches
+// its type.
+func parsePatch(override v1alpha1.ResourceOverride) ([]byte, error) {
+	patch, err := yaml.YAMLToJSON([]byte(override.Patch))
+	if err != nil {
+		return nil, fmt.Errorf("parsing patch: %w", err)
+	}
+	switch override.Type {
+	case v1alpha1.PatchTypeStrategic, v1alpha1.PatchTypeMerge, "":
+		if err := json.Unmarshal(patch, &map[string]interface{}{}); err != nil {
+			return nil, fmt.Errorf("%s patches must be objects: %w", patchType(override.Type), err)
+		}
+	case v1alpha1.PatchTypeJSON6902:
+		if _, err := jsonpatch.DecodePatch(patch); err != nil {
+			return nil, fmt.Errorf("json6902 patches must be lists of operations: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", override.Type)
+	}
+	return patch, nil
+}
+
+// applyPatch returns the content of the resource patched with the JSON patch of the given type.
+// Patches cannot change the identity of the resource.
+func applyPatch(u *unstructured.Unstructured, patchType v1alpha1.PatchType, patch []byte) (map[string]interface{}, error) {
+	original, err := u.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var patched []byte
+	switch patchType {
+	case v1alpha1.PatchTypeMerge:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case v1alpha1.PatchTypeJSON6902:
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		var schema runtime.Object
+		if schema, err = overrideScheme.New(u.GroupVersionKind()); err != nil {
+			return nil, fmt.Errorf("strategic patches are not supported by %s, use a merge or json6902 patch", u.GetKind())
+		}
+		patched, err = strategicpatch.StrategicMergePatch(original, patch, schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return nil, err
+	}
+	if result.GroupVersionKind() != u.GroupVersionKind() || result.GetName() != u.GetName() || result.GetNamespace() != u.GetNamespace() {
+		return nil, fmt.Errorf("patches cannot change the apiVersion, kind, name or namespace")
+	}
+	return result.Object, nil
+}
+
+func patchType(t v1alpha1.PatchType) v1alpha1.PatchType {
+	if t == "" {
+		return v1alpha1.PatchTypeStrategic
+	}
+	return t
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"path"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func TestOverrides(t *testing.T) {
+	cases := []struct {
+		name     string
+		override v1alpha1.ResourceOverride
+		kind     string
+		field    []string
+		expected types.GomegaMatcher
+		err      string
+	}{
+		{
+			name: "strategic patch merges containers by name",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment", Name: "controller"},
+				Patch:  "spec:\n  template:\n    spec:\n      containers:\n      - name: controller\n        args: [--verbose]\n",
+			},
+			kind:  "Deployment",
+			field: []string{"spec", "template", "spec", "containers"},
+			expected: And(HaveLen(1), ContainElement(And(
+				HaveKeyWithValue("name", "controller"),
+				HaveKeyWithValue("args", ConsistOf("--verbose")),
+				HaveKey("image"),
+			))),
+		},
+		{
+			name: "merge patch",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment"},
+				Type:   v1alpha1.PatchTypeMerge,
+				Patch:  `{"spec": {"template": {"spec": {"priorityClassName": "system-cluster-critical"}}}}`,
+			},
+			kind:     "Deployment",
+			field:    []string{"spec", "template", "spec", "priorityClassName"},
+			expected: Equal("system-cluster-critical"),
+		},
+		{
+			name: "json6902 patch of a custom resource",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "ClusterBuildStrategy", Name: "buildkit"},
+				Type:   v1alpha1.PatchTypeJSON6902,
+				Patch:  "- op: replace\n  path: /spec/steps/1/name\n  value: publish\n",
+			},
+			kind:     "ClusterBuildStrategy",
+			field:    []string{"spec", "steps"},
+			expected: ContainElement(HaveKeyWithValue("name", "publish")),
+		},
+		{
+			name: "other names are not patched",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "ClusterBuildStrategy", Name: "buildah"},
+				Type:   v1alpha1.PatchTypeMerge,
+				Patch:  `{"metadata": {"labels": {"patched": "true"}}}`,
+			},
+			kind:     "ClusterBuildStrategy",
+			field:    []string{"metadata", "labels"},
+			expected: BeNil(),
+		},
+		{
+			name: "strategic patches of custom resources are rejected",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "ClusterBuildStrategy"},
+				Patch:  `{"metadata": {"labels": {"patched": "true"}}}`,
+			},
+			kind:     "ClusterBuildStrategy",
+			field:    []string{"metadata", "labels"},
+			expected: BeNil(),
+			err:      `overrides[0]: patching ClusterBuildStrategy "buildkit": strategic patches are not supported by ClusterBuildStrategy`,
+		},
+		{
+			name: "invalid patch",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment"},
+				Type:   v1alpha1.PatchTypeJSON6902,
+				Patch:  `{"op": "remove"}`,
+			},
+			err: "overrides[0]: json6902 patches must be lists of operations",
+		},
+		{
+			name: "renames are rejected",
+			override: v1alpha1.ResourceOverride{
+				Target: v1alpha1.OverrideTarget{Kind: "Deployment"},
+				Type:   v1alpha1.PatchTypeMerge,
+				Patch:  `{"metadata": {"name": "renamed"}}`,
+			},
+			kind:     "Deployment",
+			field:    []string{"metadata", "name"},
+			expected: Equal("controller"),
+			err:      "patches cannot change the apiVersion, kind, name or namespace",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-strategy-image.yaml")))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			overrides := NewOverrides([]v1alpha1.ResourceOverride{tc.override})
+			newManifest, err := manifest.Transform(overrides.Transformer())
+			g.Expect(err).NotTo(HaveOccurred(), "overrides should never fail the transformation")
+			if tc.err != "" {
+				g.Expect(overrides.Errors()).To(ConsistOf(MatchError(ContainSubstring(tc.err))))
+			} else {
+				g.Expect(overrides.Errors()).To(BeEmpty())
+			}
+			if tc.kind == "" {
+				return
+			}
+			u := newManifest.Filter(mf.ByKind(tc.kind)).Resources()[0]
+			value, _, _ := unstructured.NestedFieldNoCopy(u.Object, tc.field...)
+			g.Expect(value).To(tc.expected)
+		})
+	}
+}
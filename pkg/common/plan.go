@@ -0,0 +1,125 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+// planFieldsDepth is the depth of the field paths reported for the planned updates.
+const planFieldsDepth = 4
+
+// PlanApply returns the changes applying the manifest would make to the cluster: the resources
+// which do not exist are created, and the ones differing from the manifest are updated. Each
+// change is submitted to the API server as a dry-run, and its error is recorded on the change.
+// Resources whose kind is not served yet, as their CRD is part of the plan, are not validated.
+func PlanApply(manifest manifestival.Manifest) ([]v1alpha1.PlannedChange, error) {
+	changes := []v1alpha1.PlannedChange{}
+	for _, u := range manifest.Resources() {
+		resource := manifest.Filter(sameResource(&u))
+		change := v1alpha1.PlannedChange{ResourceReference: ResourceReferences([]unstructured.Unstructured{u})[0]}
+		_, err := manifest.Client.Get(&u)
+		switch {
+		case meta.IsNoMatchError(err):
+			change.Action = v1alpha1.PlanActionCreate
+			changes = append(changes, change)
+			continue
+		case errors.IsNotFound(err):
+			change.Action = v1alpha1.PlanActionCreate
+		case err != nil:
+			return nil, err
+		default:
+			patches, err := resource.DryRun()
+			if err != nil {
+				return nil, err
+			}
+			if len(patches) == 0 {
+				continue
+			}
+			change.Action = v1alpha1.PlanActionUpdate
+			change.Fields = patchFields(patches[0])
+		}
+		if err := resource.Apply(manifestival.DryRunAll); err != nil {
+			change.Error = err.Error()
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// PlanDelete returns the deletion of the resources of the manifest which exist on the cluster.
+// Each deletion is submitted to the API server as a dry-run, and its error is recorded on the
+// change.
+func PlanDelete(manifest manifestival.Manifest) ([]v1alpha1.PlannedChange, error) {
+	changes := []v1alpha1.PlannedChange{}
+	for _, u := range manifest.Resources() {
+		_, err := manifest.Client.Get(&u)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		change := v1alpha1.PlannedChange{
+			Action:            v1alpha1.PlanActionDelete,
+			ResourceReference: ResourceReferences([]unstructured.Unstructured{u})[0],
+		}
+		if err := manifest.Filter(sameResource(&u)).Delete(manifestival.DryRunAll); err != nil {
+			change.Error = err.Error()
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// PlanCounts returns the number of creates, updates and deletes of the changes.
+func PlanCounts(changes []v1alpha1.PlannedChange) (int32, int32, int32) {
+	counts := map[v1alpha1.PlanAction]int32{}
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	return counts[v1alpha1.PlanActionCreate], counts[v1alpha1.PlanActionUpdate], counts[v1alpha1.PlanActionDelete]
+}
+
+// sameResource selects the resources with the kind, namespace and name of the given one.
+func sameResource(u *unstructured.Unstructured) manifestival.Predicate {
+	return func(other *unstructured.Unstructured) bool {
+		return other.GroupVersionKind() == u.GroupVersionKind() &&
+			other.GetNamespace() == u.GetNamespace() &&
+			other.GetName() == u.GetName()
+	}
+}
+
+// patchFields returns the sorted paths of the fields set by the merge patch, down to
+// planFieldsDepth. The identity of the resource, which the dry-run adds to its patches, and the
+// strategic merge patch directives are left out.
+func patchFields(patch manifestival.MergePatch) []string {
+	fields := []string{}
+	var walk func(value map[string]interface{}, path []string)
+	walk = func(value map[string]interface{}, path []string) {
+		for key, child := range value {
+			if strings.HasPrefix(key, "$") {
+				continue
+			}
+			childPath := append(append([]string{}, path...), key)
+			switch strings.Join(childPath, ".") {
+			case "apiVersion", "kind", "metadata.name":
+				continue
+			}
+			if object, ok := child.(map[string]interface{}); ok && len(object) > 0 && len(childPath) < planFieldsDepth {
+				walk(object, childPath)
+				continue
+			}
+			fields = append(fields, strings.Join(childPath, "."))
+		}
+	}
+	walk(patch, nil)
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"testing"
+
+	mfc "github.com/manifestival/controller-runtime-client"
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func planConfigMap(name string, data map[string]interface{}) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{"data": data}}
+	u.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	u.SetNamespace("namespace")
+	u.SetName(name)
+	return u
+}
+
+func TestPlan(t *testing.T) {
+	g := NewWithT(t)
+	c := mfc.NewClient(fake.NewClientBuilder().Build())
+
+	applied, err := mf.ManifestFrom(mf.Slice([]unstructured.Unstructured{
+		planConfigMap("unchanged", map[string]interface{}{"key": "value"}),
+		planConfigMap("changed", map[string]interface{}{"key": "value"}),
+	}), mf.UseClient(c))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(applied.Apply()).To(Succeed())
+
+	desired, err := mf.ManifestFrom(mf.Slice([]unstructured.Unstructured{
+		planConfigMap("unchanged", map[string]interface{}{"key": "value"}),
+		planConfigMap("changed", map[string]interface{}{"key": "other"}),
+		planConfigMap("missing", map[string]interface{}{"key": "value"}),
+	}), mf.UseClient(c))
+	g.Expect(err).NotTo(HaveOccurred())
+	changes, err := PlanApply(desired)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(ConsistOf(
+		v1alpha1.PlannedChange{
+			Action:            v1alpha1.PlanActionUpdate,
+			ResourceReference: v1alpha1.ResourceReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "namespace", Name: "changed"},
+			Fields:            []string{"data.key"},
+		},
+		v1alpha1.PlannedChange{
+			Action:            v1alpha1.PlanActionCreate,
+			ResourceReference: v1alpha1.ResourceReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "namespace", Name: "missing"},
+		},
+	))
+
+	changes, err = PlanDelete(desired)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changes).To(HaveLen(2))
+	for _, change := range changes {
+		g.Expect(change.Action).To(Equal(v1alpha1.PlanActionDelete))
+		g.Expect(change.Name).NotTo(Equal("missing"))
+	}
+	creates, updates, deletes := PlanCounts(changes)
+	g.Expect([]int32{creates, updates, deletes}).To(Equal([]int32{0, 0, 2}))
+
+	// planning does not change the cluster
+	_, err = c.Get(&unstructured.Unstructured{Object: desired.Resources()[2].Object})
+	g.Expect(err).To(HaveOccurred())
+	live, err := c.Get(&unstructured.Unstructured{Object: desired.Resources()[1].Object})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(live.Object["data"]).To(HaveKeyWithValue("key", "value"))
+}
+
+func TestPatchFields(t *testing.T) {
+	g := NewWithT(t)
+	patch := mf.MergePatch{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "controller",
+			"labels": map[string]interface{}{"app": "controller"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"$setElementOrder/containers": []interface{}{},
+					"containers":                  []interface{}{map[string]interface{}{"name": "controller"}},
+					"nodeSelector":                map[string]interface{}{"role": "infra"},
+				},
+			},
+		},
+	}
+	g.Expect(patchFields(patch)).To(Equal([]string{
+		"metadata.labels.app",
+		"spec.replicas",
+		"spec.template.spec.containers",
+		"spec.template.spec.nodeSelector",
+	}))
+}
@@ -0,0 +1,124 @@
+package common
+
+import (
+	"github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+// ProxyEnv returns the environment variables of the given proxy, for the fields which are set.
+func ProxyEnv(proxy *v1alpha1.ProxySpec) []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+	if proxy == nil {
+		return env
+	}
+	for _, e := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+		{Name: "NO_PROXY", Value: proxy.NoProxy},
+	} {
+		if e.Value != "" {
+			env = append(env, e)
+		}
+	}
+	return env
+}
+
+// MergeProxy returns the proxy with the fields of the override, falling back to the fields of the
+// base which are not set in the override.
+func MergeProxy(base, override *v1alpha1.ProxySpec) *v1alpha1.ProxySpec {
+	if base == nil {
+		return override
+	}
+	merged := base.DeepCopy()
+	if override == nil {
+		return merged
+	}
+	if override.HTTPProxy != "" {
+		merged.HTTPProxy = override.HTTPProxy
+	}
+	if override.HTTPSProxy != "" {
+		merged.HTTPSProxy = override.HTTPSProxy
+	}
+	if override.NoProxy != "" {
+		merged.NoProxy = override.NoProxy
+	}
+	return merged
+}
+
+// InjectProxy sets the proxy environment variables on the containers and init containers of
+// Deployments, replacing their existing values, and on the steps of ClusterBuildStrategies, where
+// they are only added when the step does not set them already.
+func InjectProxy(proxy *v1alpha1.ProxySpec) manifestival.Transformer {
+	env := ProxyEnv(proxy)
+	return func(u *unstructured.Unstructured) error {
+		if len(env) == 0 {
+			return nil
+		}
+		switch u.GetKind() {
+		case "Deployment":
+			d := &appsv1.Deployment{}
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)
+			if err != nil {
+				return err
+			}
+
+			setContainersEnv(d.Spec.Template.Spec.InitContainers, env)
+			setContainersEnv(d.Spec.Template.Spec.Containers, env)
+			unstrObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+			if err != nil {
+				return err
+			}
+			u.SetUnstructuredContent(unstrObj)
+		case "ClusterBuildStrategy":
+			return transformStrategySteps(u, func(step map[string]interface{}) error {
+				stepEnv, _, err := unstructured.NestedSlice(step, "env")
+				if err != nil {
+					return err
+				}
+				for _, e := range env {
+					if !hasNamedItem(stepEnv, e.Name) {
+						stepEnv = append(stepEnv, map[string]interface{}{"name": e.Name, "value": e.Value})
+					}
+				}
+				step["env"] = stepEnv
+				return nil
+			})
+		}
+		return nil
+	}
+}
+
+// setContainersEnv sets the environment variables on the containers, replacing the existing ones
+// with the same name.
+func setContainersEnv(containers []corev1.Container, env []corev1.EnvVar) {
+	for i := range containers {
+		for _, e := range env {
+			replaced := false
+			for j := range containers[i].Env {
+				if containers[i].Env[j].Name == e.Name {
+					containers[i].Env[j] = e
+					replaced = true
+				}
+			}
+			if !replaced {
+				containers[i].Env = append(containers[i].Env, e)
+			}
+		}
+	}
+}
+
+// hasNamedItem tells whether the unstructured items, such as environment variables or volumes,
+// contain one with the given name.
+func hasNamedItem(items []interface{}, name string) bool {
+	for _, item := range items {
+		if e, ok := item.(map[string]interface{}); ok && e["name"] == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"path"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func TestMergeProxy(t *testing.T) {
+	g := NewWithT(t)
+	cluster := &v1alpha1.ProxySpec{HTTPProxy: "http://cluster:3128", HTTPSProxy: "http://cluster:3128", NoProxy: ".cluster.local"}
+	override := &v1alpha1.ProxySpec{HTTPSProxy: "http://proxy.example.com:3128"}
+
+	g.Expect(MergeProxy(nil, override)).To(Equal(override))
+	g.Expect(MergeProxy(cluster, nil)).To(Equal(cluster))
+	g.Expect(MergeProxy(cluster, override)).To(Equal(&v1alpha1.ProxySpec{
+		HTTPProxy:  "http://cluster:3128",
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    ".cluster.local",
+	}))
+	g.Expect(cluster.HTTPSProxy).To(Equal("http://cluster:3128"), "the base should not be modified")
+}
+
+func TestInjectProxy(t *testing.T) {
+	g := NewWithT(t)
+	proxy := &v1alpha1.ProxySpec{HTTPProxy: "http://proxy:3128", NoProxy: ".svc"}
+
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-image.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	strategies, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-strategy-image.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	manifest = manifest.Append(strategies)
+
+	unchanged, err := manifest.Transform(InjectProxy(nil))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(unchanged.Resources()).To(Equal(manifest.Resources()))
+
+	// a step setting NO_PROXY keeps its value
+	resources := manifest.Resources()
+	strategy := &resources[len(resources)-1]
+	steps, _, _ := unstructured.NestedSlice(strategy.Object, "spec", "steps")
+	steps[0].(map[string]interface{})["env"] = []interface{}{map[string]interface{}{"name": "NO_PROXY", "value": "*"}}
+	g.Expect(unstructured.SetNestedSlice(strategy.Object, steps, "spec", "steps")).To(Succeed())
+	manifest, err = mf.ManifestFrom(mf.Slice(resources))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newManifest, err := manifest.Transform(InjectProxy(proxy))
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, u := range newManifest.Filter(mf.ByKind("Deployment")).Resources() {
+		d := &appsv1.Deployment{}
+		g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)).To(Succeed())
+		containers := append(d.Spec.Template.Spec.InitContainers, d.Spec.Template.Spec.Containers...)
+		g.Expect(containers).NotTo(BeEmpty())
+		for _, c := range containers {
+			g.Expect(c.Env).To(ContainElements(
+				corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy:3128"},
+				corev1.EnvVar{Name: "NO_PROXY", Value: ".svc"},
+			), "checking the env of %s/%s", d.Name, c.Name)
+			g.Expect(c.Env).NotTo(ContainElement(HaveField("Name", "HTTPS_PROXY")))
+		}
+	}
+
+	strategy = &newManifest.Filter(mf.ByKind("ClusterBuildStrategy")).Resources()[0]
+	steps, _, _ = unstructured.NestedSlice(strategy.Object, "spec", "steps")
+	g.Expect(steps[0].(map[string]interface{})["env"]).To(Equal([]interface{}{
+		map[string]interface{}{"name": "NO_PROXY", "value": "*"},
+		map[string]interface{}{"name": "HTTP_PROXY", "value": "http://proxy:3128"},
+	}))
+	g.Expect(steps[1].(map[string]interface{})["env"]).To(Equal([]interface{}{
+		map[string]interface{}{"name": "HTTP_PROXY", "value": "http://proxy:3128"},
+		map[string]interface{}{"name": "NO_PROXY", "value": ".svc"},
+	}))
+}
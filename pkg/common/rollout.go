@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DeploymentRolloutStatus returns true when the rollout of the given Deployment is complete, that
+// is when its latest spec was observed and all of its replicas are updated and available.
+// Otherwise, the returned message describes the progress of the rollout.
+func DeploymentRolloutStatus(d *appsv1.Deployment) (bool, string) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, "waiting for the deployment spec update to be observed"
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("%d out of %d new replicas have been updated", d.Status.UpdatedReplicas, replicas)
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d old replicas are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	}
+	if d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d of %d updated replicas are available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	}
+	return true, ""
+}
+
+// PodFailure returns the reason and message explaining why one of the given pods is not running,
+// such as a container waiting in "CrashLoopBackOff" or a pod that cannot be scheduled. Containers
+// that are still being created are only reported when no other failure is found.
+func PodFailure(pods []corev1.Pod) (string, string) {
+	var pendingReason, pendingMessage string
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				return condition.Reason, fmt.Sprintf("pod %s: %s", pod.Name, condition.Message)
+			}
+		}
+		statuses := slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses)
+		for _, status := range statuses {
+			if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+				message := fmt.Sprintf("pod %s: container %s: %s", pod.Name, status.Name, waiting.Reason)
+				if waiting.Message != "" {
+					message = fmt.Sprintf("%s: %s", message, waiting.Message)
+				}
+				if waiting.Reason == "ContainerCreating" || waiting.Reason == "PodInitializing" {
+					if pendingReason == "" {
+						pendingReason, pendingMessage = waiting.Reason, message
+					}
+					continue
+				}
+				return waiting.Reason, message
+			}
+			if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+				return terminated.Reason, fmt.Sprintf("pod %s: container %s exited with code %d", pod.Name, status.Name, terminated.ExitCode)
+			}
+		}
+	}
+	return pendingReason, pendingMessage
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+
+	cases := []struct {
+		name      string
+		status    appsv1.DeploymentStatus
+		available bool
+	}{
+		{
+			name:   "spec update not observed",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1},
+		},
+		{
+			name:   "replicas not updated",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1},
+		},
+		{
+			name:   "old replicas pending termination",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2},
+		},
+		{
+			name:   "updated replicas not available",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+		{
+			name:      "rolled out",
+			status:    appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			available: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			d := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tc.status,
+			}
+			available, message := DeploymentRolloutStatus(d)
+			g.Expect(available).To(Equal(tc.available))
+			if tc.available {
+				g.Expect(message).To(BeEmpty())
+			} else {
+				g.Expect(message).NotTo(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestPodFailure(t *testing.T) {
+	creating := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "creating"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "controller",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}},
+		},
+	}
+	crashing := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "crashing"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "controller",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CrashLoopBackOff",
+					Message: "back-off 5m0s restarting failed container",
+				}},
+			}},
+		},
+	}
+	unschedulable := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "unschedulable"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available",
+			}},
+		},
+	}
+	failedInit := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed-init"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "init",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		pods    []corev1.Pod
+		reason  string
+		message string
+	}{
+		{
+			name: "no pods",
+		},
+		{
+			name:    "container creating",
+			pods:    []corev1.Pod{creating},
+			reason:  "ContainerCreating",
+			message: "pod creating: container controller: ContainerCreating",
+		},
+		{
+			name:    "crash loop reported before container creating",
+			pods:    []corev1.Pod{creating, crashing},
+			reason:  "CrashLoopBackOff",
+			message: "pod crashing: container controller: CrashLoopBackOff: back-off 5m0s restarting failed container",
+		},
+		{
+			name:    "unschedulable",
+			pods:    []corev1.Pod{unschedulable},
+			reason:  corev1.PodReasonUnschedulable,
+			message: "pod unschedulable: 0/3 nodes are available",
+		},
+		{
+			name:    "init container failed",
+			pods:    []corev1.Pod{failedInit},
+			reason:  "Error",
+			message: "pod failed-init: container init exited with code 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			reason, message := PodFailure(tc.pods)
+			g.Expect(reason).To(Equal(tc.reason))
+			g.Expect(message).To(Equal(tc.message))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/manifestival/manifestival"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// TrustedCAConfigMap is the ConfigMap holding the trusted CA bundle in the target namespace,
+	// mounted by the components.
+	TrustedCAConfigMap = "shipwright-trusted-ca"
+	// TrustedCAKey is the key of the CA bundle in the trusted CA ConfigMap.
+	TrustedCAKey = "ca-bundle.crt"
+	// TrustedCAMountPath is the directory where the trusted CA bundle is mounted, added to
+	// SSL_CERT_DIR.
+	TrustedCAMountPath = "/etc/shipwright/trusted-ca"
+	// TrustedCAHashAnnotation is set on the pod templates with the hash of the trusted CA bundle,
+	// so that the components are restarted when it changes.
+	TrustedCAHashAnnotation = "operator.shipwright.io/trusted-ca-hash"
+	// InjectTrustedCABundleLabel asks OpenShift to inject the cluster trusted CA bundle in the
+	// labeled ConfigMap.
+	InjectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// trustedCAVolume is the name of the volume of the trusted CA bundle.
+	trustedCAVolume = "shipwright-trusted-ca"
+	// sslCertDirEnv lists the directories of the trusted certificates.
+	sslCertDirEnv = "SSL_CERT_DIR"
+	// systemCertDir is the directory of the certificates of the images, kept in SSL_CERT_DIR.
+	systemCertDir = "/etc/ssl/certs"
+)
+
+// TrustedCAConfigMapObject returns the trusted CA ConfigMap holding the given bundle. When inject
+// is true, the ConfigMap is left empty and labeled for OpenShift to inject the cluster bundle.
+func TrustedCAConfigMapObject(bundle string, inject bool) unstructured.Unstructured {
+	configMap := &corev1.ConfigMap{}
+	configMap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	configMap.Name = TrustedCAConfigMap
+	if inject {
+		configMap.Labels = map[string]string{InjectTrustedCABundleLabel: "true"}
+	} else {
+		configMap.Data = map[string]string{TrustedCAKey: bundle}
+	}
+	obj, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(configMap)
+	u := unstructured.Unstructured{Object: obj}
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	return u
+}
+
+// TrustedCAHash returns the hash identifying the trusted CA bundle.
+func TrustedCAHash(bundle string) string {
+	hash := sha256.Sum256([]byte(bundle))
+	return hex.EncodeToString(hash[:8])
+}
+
+// TrustedCA mounts the trusted CA ConfigMap in the containers and init containers of Deployments,
+// adding its directory to SSL_CERT_DIR. The hash, when not empty, is set on the pod templates of
+// the Deployments. The ClusterBuildStrategies are left unchanged, as their steps run in the
+// namespaces of the builds, where the ConfigMap does not exist.
+func TrustedCA(hash string) manifestival.Transformer {
+	sslCertDir := TrustedCAMountPath + ":" + systemCertDir
+	return func(u *unstructured.Unstructured) error {
+		if u.GetKind() != "Deployment" {
+			return nil
+		}
+		d := &appsv1.Deployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d); err != nil {
+			return err
+		}
+
+		podSpec := &d.Spec.Template.Spec
+		if !hasVolume(podSpec.Volumes, trustedCAVolume) {
+			podSpec.Volumes = append(podSpec.Volumes, trustedCAVolumeSource())
+		}
+		env := []corev1.EnvVar{{Name: sslCertDirEnv, Value: sslCertDir}}
+		mount := corev1.VolumeMount{Name: trustedCAVolume, MountPath: TrustedCAMountPath, ReadOnly: true}
+		for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+			setContainersEnv(containers, env)
+			for i := range containers {
+				if !hasVolumeMount(containers[i].VolumeMounts, trustedCAVolume) {
+					containers[i].VolumeMounts = append(containers[i].VolumeMounts, mount)
+				}
+			}
+		}
+		if hash != "" {
+			if d.Spec.Template.Annotations == nil {
+				d.Spec.Template.Annotations = map[string]string{}
+			}
+			d.Spec.Template.Annotations[TrustedCAHashAnnotation] = hash
+		}
+		unstrObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+		if err != nil {
+			return err
+		}
+		u.SetUnstructuredContent(unstrObj)
+		return nil
+	}
+}
+
+// trustedCAVolumeSource returns the volume of the trusted CA ConfigMap. The ConfigMap is optional,
+// as on OpenShift the bundle is only injected once it is created.
+func trustedCAVolumeSource() corev1.Volume {
+	optional := true
+	return corev1.Volume{
+		Name: trustedCAVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: TrustedCAConfigMap},
+				Items:                []corev1.KeyToPath{{Key: TrustedCAKey, Path: TrustedCAKey}},
+				Optional:             &optional,
+			},
+		},
+	}
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(mounts []corev1.VolumeMount, name string) bool {
+	for _, m := range mounts {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
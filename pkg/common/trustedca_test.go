@@ -0,0 +1,55 @@
+package common
+
+import (
+	"path"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTrustedCAConfigMapObject(t *testing.T) {
+	g := NewWithT(t)
+
+	configMap := TrustedCAConfigMapObject("bundle", false)
+	g.Expect(configMap.GetKind()).To(Equal("ConfigMap"))
+	g.Expect(configMap.GetName()).To(Equal(TrustedCAConfigMap))
+	data, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+	g.Expect(data).To(Equal(map[string]string{TrustedCAKey: "bundle"}))
+	g.Expect(configMap.GetLabels()).To(BeEmpty())
+
+	injected := TrustedCAConfigMapObject("", true)
+	g.Expect(injected.GetLabels()).To(Equal(map[string]string{InjectTrustedCABundleLabel: "true"}))
+	_, found, _ := unstructured.NestedFieldNoCopy(injected.Object, "data")
+	g.Expect(found).To(BeFalse(), "the injected bundle should not be overwritten")
+}
+
+func TestTrustedCA(t *testing.T) {
+	g := NewWithT(t)
+
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-replace-strategy-image.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	strategy := manifest.Filter(mf.ByKind("ClusterBuildStrategy")).Resources()[0]
+
+	// applying the transformer twice is idempotent
+	newManifest, err := manifest.Transform(TrustedCA("abc"), TrustedCA("abc"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	d := &appsv1.Deployment{}
+	u := newManifest.Filter(mf.ByKind("Deployment")).Resources()[0]
+	g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d)).To(Succeed())
+	g.Expect(d.Spec.Template.Annotations).To(HaveKeyWithValue(TrustedCAHashAnnotation, "abc"))
+	g.Expect(d.Spec.Template.Spec.Volumes).To(HaveLen(1))
+	g.Expect(d.Spec.Template.Spec.Volumes[0].ConfigMap.Name).To(Equal(TrustedCAConfigMap))
+	for _, c := range append(d.Spec.Template.Spec.InitContainers, d.Spec.Template.Spec.Containers...) {
+		g.Expect(c.Env).To(Equal([]corev1.EnvVar{{Name: "SSL_CERT_DIR", Value: "/etc/shipwright/trusted-ca:/etc/ssl/certs"}}))
+		g.Expect(c.VolumeMounts).To(Equal([]corev1.VolumeMount{{Name: "shipwright-trusted-ca", MountPath: TrustedCAMountPath, ReadOnly: true}}))
+	}
+
+	// the build strategy steps run in the namespaces of the builds, without the ConfigMap
+	g.Expect(newManifest.Filter(mf.ByKind("ClusterBuildStrategy")).Resources()[0]).To(Equal(strategy))
+}
@@ -20,6 +20,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
 )
 
 // SetupManifestival instantiates a Manifestival instance for the provided file or directory
@@ -75,6 +77,73 @@ func ImagesFromEnv(prefix string) map[string]string {
 	return images
 }
 
+// MergeImages returns the base images with the given overrides applied on top. Override keys are
+// normalized the same way container and env var names are when images are replaced.
+func MergeImages(base map[string]string, overrides map[string]string) map[string]string {
+	images := map[string]string{}
+	for k, v := range base {
+		images[k] = v
+	}
+	for k, v := range overrides {
+		images[formKey("", k)] = v
+	}
+	return images
+}
+
+// ResourceReferences returns references to the given resources.
+func ResourceReferences(resources []unstructured.Unstructured) []v1alpha1.ResourceReference {
+	references := []v1alpha1.ResourceReference{}
+	for _, obj := range resources {
+		references = append(references, v1alpha1.ResourceReference{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		})
+	}
+	return references
+}
+
+// EffectiveImages collects the images referenced by the containers and init containers of the
+// given Deployments, as well as the env vars holding images, keyed by their normalized name.
+// ClusterBuildStrategy step images are keyed by "<strategy>/<step>".
+func EffectiveImages(resources []unstructured.Unstructured) (map[string]string, error) {
+	images := map[string]string{}
+	for _, u := range resources {
+		switch u.GetKind() {
+		case "Deployment":
+			d := &appsv1.Deployment{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, d); err != nil {
+				return nil, err
+			}
+			collectContainerImages(d.Spec.Template.Spec.InitContainers, images)
+			collectContainerImages(d.Spec.Template.Spec.Containers, images)
+		case "ClusterBuildStrategy":
+			err := transformStrategySteps(u.DeepCopy(), func(step map[string]interface{}) error {
+				name, _, _ := unstructured.NestedString(step, "name")
+				image, _, _ := unstructured.NestedString(step, "image")
+				images[strategyStepKey(u.GetName(), name)] = image
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return images, nil
+}
+
+func collectContainerImages(containers []corev1.Container, images map[string]string) {
+	for _, container := range containers {
+		images[formKey("", container.Name)] = container.Image
+		for _, env := range container.Env {
+			if strings.HasSuffix(env.Name, "_IMAGE") && env.Value != "" {
+				images[formKey("", env.Name)] = env.Value
+			}
+		}
+	}
+}
+
 // toLowerCaseKeys converts key value to lower cases.
 func ToLowerCaseKeys(keyValues map[string]string) map[string]string {
 	newMap := map[string]string{}
@@ -127,7 +196,7 @@ func TruncateCRDFieldTransformer(field string, maxLength int) manifestival.Trans
 	}
 }
 
-// deploymentImages replaces container and env vars images.
+// deploymentImages replaces container, init container and env vars images.
 func DeploymentImages(images map[string]string) manifestival.Transformer {
 	return func(u *unstructured.Unstructured) error {
 		if u.GetKind() != "Deployment" {
@@ -140,8 +209,8 @@ func DeploymentImages(images map[string]string) manifestival.Transformer {
 			return err
 		}
 
-		containers := d.Spec.Template.Spec.Containers
-		replaceContainerImages(containers, images)
+		replaceContainerImages(d.Spec.Template.Spec.InitContainers, images)
+		replaceContainerImages(d.Spec.Template.Spec.Containers, images)
 		unstrObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
 		if err != nil {
 			return err
@@ -152,6 +221,53 @@ func DeploymentImages(images map[string]string) manifestival.Transformer {
 	}
 }
 
+// BuildStrategyImages replaces the step images of ClusterBuildStrategies. Steps are looked up by
+// the "<strategy>/<step>" key, normalized like container names.
+func BuildStrategyImages(images map[string]string) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		return transformStrategySteps(u, func(step map[string]interface{}) error {
+			name, _, _ := unstructured.NestedString(step, "name")
+			if url, exist := images[strategyStepKey(u.GetName(), name)]; exist {
+				step["image"] = url
+			}
+			return nil
+		})
+	}
+}
+
+// transformStrategySteps calls fn for every step of the given ClusterBuildStrategy, writing the
+// modified steps back to the object. Other kinds are left untouched.
+func transformStrategySteps(u *unstructured.Unstructured, fn func(step map[string]interface{}) error) error {
+	if u.GetKind() != "ClusterBuildStrategy" {
+		return nil
+	}
+	steps, found, err := unstructured.NestedSlice(u.Object, "spec", "steps")
+	if err != nil {
+		return fmt.Errorf("could not read steps of %s:%s, %q", u.GetKind(), u.GetName(), err)
+	}
+	if !found {
+		return nil
+	}
+	for _, s := range steps {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := fn(step); err != nil {
+			return err
+		}
+	}
+	if err := unstructured.SetNestedSlice(u.Object, steps, "spec", "steps"); err != nil {
+		return fmt.Errorf("error updating steps for %s:%s, %s", u.GetKind(), u.GetName(), err)
+	}
+	return nil
+}
+
+// strategyStepKey returns the image key of the given build strategy step.
+func strategyStepKey(strategy, step string) string {
+	return formKey("", strategy+"/"+step)
+}
+
 func formKey(prefix, arg string) string {
 	argument := strings.ToLower(arg)
 	if prefix != "" {
@@ -230,6 +346,23 @@ func InjectAnnotations(key, value string, overwritePolicy int, kinds ...string)
 	}
 }
 
+// InjectLabels adds the label key:value to the resources of the given kinds, or to all resources
+// when no kind is given.
+func InjectLabels(key, value string, kinds ...string) manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if len(kinds) != 0 && !itemInSlice(u.GetKind(), kinds) {
+			return nil
+		}
+		labels := u.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+		u.SetLabels(labels)
+		return nil
+	}
+}
+
 func itemInSlice(item string, items []string) bool {
 	for _, v := range items {
 		if v == item {
@@ -238,7 +371,3 @@ func itemInSlice(item string, items []string) bool {
 	}
 	return false
 }
-
-func IsOpenShiftPlatform() bool {
-	return os.Getenv("PLATFORM") == "openshift"
-}
@@ -12,6 +12,8 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
 )
 
 func TestImagesFromEnv(t *testing.T) {
@@ -61,6 +63,77 @@ func TestDeploymentImages(t *testing.T) {
 		Expect(err).NotTo(HaveOccurred())
 		assertDeployContainerEnvsHasImage(t, newManifest.Resources(), "IMAGE_SHIPWRIGHT_GIT_CONTAINER_IMAGE", image)
 	})
+	t.Run("replace init containers image by name", func(t *testing.T) {
+		image := "foo.bar/prepare"
+		testData := path.Join("testdata", "test-replace-strategy-image.yaml")
+
+		manifest, err := mf.ManifestFrom(mf.Recursive(testData))
+		Expect(err).NotTo(HaveOccurred())
+		newManifest, err := manifest.Transform(DeploymentImages(map[string]string{"prepare": image}))
+		Expect(err).NotTo(HaveOccurred())
+		images, err := EffectiveImages(newManifest.Resources())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(HaveKeyWithValue("prepare", image))
+		Expect(images).To(HaveKeyWithValue("controller", "busybox"))
+	})
+}
+
+func TestBuildStrategyImages(t *testing.T) {
+	RegisterFailHandler(Fail)
+	testData := path.Join("testdata", "test-replace-strategy-image.yaml")
+	manifest, err := mf.ManifestFrom(mf.Recursive(testData))
+	Expect(err).NotTo(HaveOccurred())
+
+	images := MergeImages(nil, map[string]string{
+		"buildkit/build-and-push": "registry.example.com/moby/buildkit:v0.30.0-rootless",
+		"controller":              "registry.example.com/controller",
+	})
+	newManifest, err := manifest.Transform(BuildStrategyImages(images))
+	Expect(err).NotTo(HaveOccurred())
+
+	effective, err := EffectiveImages(newManifest.Resources())
+	Expect(err).NotTo(HaveOccurred())
+	Expect(effective).To(Equal(map[string]string{
+		"prepare":                 "busybox",
+		"controller":              "busybox",
+		"buildkit/build_and_push": "registry.example.com/moby/buildkit:v0.30.0-rootless",
+		"buildkit/push":           "moby/buildkit:v0.30.0-rootless",
+	}))
+}
+
+func TestMergeImages(t *testing.T) {
+	RegisterFailHandler(Fail)
+	base := map[string]string{
+		"shipwright_build":    "ghcr.io/shipwright-io/build/shipwright-build-controller:env",
+		"git_container_image": "ghcr.io/shipwright-io/build/git:env",
+	}
+	overrides := map[string]string{
+		"shipwright-build":       "registry.example.com/shipwright-build-controller:spec",
+		"BUNDLE_CONTAINER_IMAGE": "registry.example.com/bundle:spec",
+	}
+	Expect(MergeImages(base, overrides)).To(Equal(map[string]string{
+		"shipwright_build":       "registry.example.com/shipwright-build-controller:spec",
+		"git_container_image":    "ghcr.io/shipwright-io/build/git:env",
+		"bundle_container_image": "registry.example.com/bundle:spec",
+	}))
+	Expect(base).To(HaveLen(2), "base images must not be modified")
+}
+
+func TestEffectiveImages(t *testing.T) {
+	RegisterFailHandler(Fail)
+	testData := path.Join("testdata", "test-replace-image.yaml")
+	manifest, err := mf.ManifestFrom(mf.Recursive(testData))
+	Expect(err).NotTo(HaveOccurred())
+	newManifest, err := manifest.Transform(DeploymentImages(map[string]string{"sidecar": "foo.bar/sidecar"}))
+	Expect(err).NotTo(HaveOccurred())
+
+	images, err := EffectiveImages(newManifest.Resources())
+	Expect(err).NotTo(HaveOccurred())
+	Expect(images).To(Equal(map[string]string{
+		"shipwright_controller":               "busybox",
+		"sidecar":                             "foo.bar/sidecar",
+		"image_shpwright_git_container_image": "ghcr.io/shipwright-io/build/git:v0.11.0@sha256:aecf8bdc01ea00be83e933162a0b6d063846b315fe9dcae60e4be1a34e85d514",
+	}))
 }
 
 func TestTruncateNestedFields(t *testing.T) {
@@ -205,3 +278,13 @@ func TestBoolFromEnvVar(t *testing.T) {
 		Expect(BoolFromEnvVar("USE_MANAGED_CERTS")).To(Equal(tc.expectedResult))
 	}
 }
+
+func TestResourceReferences(t *testing.T) {
+	g := NewWithT(t)
+	manifest, err := mf.ManifestFrom(mf.Recursive(path.Join("testdata", "test-deployment-overrides.yaml")))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ResourceReferences(manifest.Resources())).To(Equal([]v1alpha1.ResourceReference{
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: BuildControllerDeployment},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: BuildWebhookDeployment},
+	}))
+}
@@ -0,0 +1,165 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/discovery"
+)
+
+// PlatformEnv overrides the detected platform: "openshift" forces OpenShift, and any other value
+// forces a plain Kubernetes platform.
+const PlatformEnv = "PLATFORM"
+
+// Capability is an optional feature of the cluster the operator runs on.
+type Capability string
+
+const (
+	// Routes indicates that OpenShift Routes are available.
+	Routes Capability = "Routes"
+	// SecurityContextConstraints indicates that OpenShift SecurityContextConstraints are enforced.
+	SecurityContextConstraints Capability = "SecurityContextConstraints"
+	// ServiceCA indicates that the OpenShift service CA issues serving certificates.
+	ServiceCA Capability = "ServiceCA"
+	// PrometheusOperator indicates that the Prometheus Operator monitoring API is available.
+	PrometheusOperator Capability = "PrometheusOperator"
+	// CertManager indicates that cert-manager is installed.
+	CertManager Capability = "CertManager"
+	// GatewayAPI indicates that the Kubernetes Gateway API is installed.
+	GatewayAPI Capability = "GatewayAPI"
+	// ClusterProxy indicates that the cluster-wide egress proxy is configured with the OpenShift
+	// Proxy API.
+	ClusterProxy Capability = "ClusterProxy"
+)
+
+// capabilities lists the known capabilities.
+var capabilities = []Capability{
+	Routes, SecurityContextConstraints, ServiceCA, PrometheusOperator, CertManager, GatewayAPI, ClusterProxy,
+}
+
+// capabilityGroups maps the API groups served by the cluster to the capabilities they provide.
+var capabilityGroups = map[string][]Capability{
+	"config.openshift.io":       {ServiceCA, ClusterProxy},
+	"route.openshift.io":        {Routes},
+	"security.openshift.io":     {SecurityContextConstraints},
+	"monitoring.coreos.com":     {PrometheusOperator},
+	"cert-manager.io":           {CertManager},
+	"gateway.networking.k8s.io": {GatewayAPI},
+}
+
+// openShiftCapabilities are the capabilities of an OpenShift cluster, assumed when the platform
+// is forced to OpenShift with the PLATFORM environment variable.
+var openShiftCapabilities = []Capability{Routes, SecurityContextConstraints, ServiceCA}
+
+// platformCapabilities are the capabilities provided by the OpenShift APIs, which depend on the
+// platform, unlike the ones of the add-ons installed on the cluster.
+var platformCapabilities = []Capability{Routes, SecurityContextConstraints, ServiceCA, ClusterProxy}
+
+// Capabilities is a set of capabilities.
+type Capabilities map[Capability]bool
+
+// Has tells whether the capability is in the set.
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+// List returns the capabilities of the set, sorted by name.
+func (c Capabilities) List() []string {
+	names := []string{}
+	for capability, ok := range c {
+		if ok {
+			names = append(names, string(capability))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Platform describes the cluster the operator runs on.
+type Platform struct {
+	// OpenShift tells whether the cluster is an OpenShift cluster.
+	OpenShift bool
+	// Capabilities holds the optional features of the cluster.
+	Capabilities Capabilities
+}
+
+// Name returns the name of the platform, either "OpenShift" or "Kubernetes".
+func (p *Platform) Name() string {
+	if p.OpenShift {
+		return "OpenShift"
+	}
+	return "Kubernetes"
+}
+
+// Detect discovers the platform and its capabilities from the API groups served by the cluster.
+// The PLATFORM environment variable, when set, overrides whether the cluster is an OpenShift
+// cluster.
+func Detect(client discovery.DiscoveryInterface) (*Platform, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	p := &Platform{Capabilities: Capabilities{}}
+	for _, group := range groups.Groups {
+		if group.Name == "config.openshift.io" {
+			p.OpenShift = true
+		}
+		for _, capability := range capabilityGroups[group.Name] {
+			p.Capabilities[capability] = true
+		}
+	}
+	return p.withOverride(), nil
+}
+
+// New returns the platform with the given name, "openshift" or "kubernetes", and capabilities,
+// for the uses which have no cluster to detect it from. OpenShift platforms have the capabilities
+// of every OpenShift cluster.
+func New(name string, names []string) (*Platform, error) {
+	p := &Platform{Capabilities: Capabilities{}}
+	switch strings.ToLower(name) {
+	case "openshift":
+		p.OpenShift = true
+		for _, capability := range openShiftCapabilities {
+			p.Capabilities[capability] = true
+		}
+	case "kubernetes", "":
+	default:
+		return nil, fmt.Errorf("unknown platform %q, expected openshift or kubernetes", name)
+	}
+	for _, name := range names {
+		i := slices.IndexFunc(capabilities, func(c Capability) bool { return strings.EqualFold(string(c), name) })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown capability %q", name)
+		}
+		p.Capabilities[capabilities[i]] = true
+	}
+	return p, nil
+}
+
+// FromEnv returns the platform set by the PLATFORM environment variable, without detection.
+func FromEnv() *Platform {
+	return (&Platform{Capabilities: Capabilities{}}).withOverride()
+}
+
+// withOverride applies the PLATFORM environment variable to the platform. The capabilities which
+// depend on the platform are reset to the ones of the forced platform, while the detected add-ons
+// are kept.
+func (p *Platform) withOverride() *Platform {
+	value, ok := os.LookupEnv(PlatformEnv)
+	if !ok || value == "" {
+		return p
+	}
+	p.OpenShift = value == "openshift"
+	for _, capability := range platformCapabilities {
+		delete(p.Capabilities, capability)
+	}
+	if p.OpenShift {
+		for _, capability := range openShiftCapabilities {
+			p.Capabilities[capability] = true
+		}
+	}
+	return p
+}
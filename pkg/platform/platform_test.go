@@ -0,0 +1,99 @@
+package platform
+
+import (
+	"testing"
+
+	o "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func discoveryClient(groups ...string) *fakediscovery.FakeDiscovery {
+	resources := []*metav1.APIResourceList{}
+	for _, group := range groups {
+		resources = append(resources, &metav1.APIResourceList{GroupVersion: group + "/v1"})
+	}
+	return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+}
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		name                 string
+		groups               []string
+		env                  string
+		expectedOpenShift    bool
+		expectedCapabilities []string
+	}{
+		{
+			name:                 "Kubernetes",
+			groups:               []string{"apps"},
+			expectedCapabilities: []string{},
+		},
+		{
+			name:                 "Kubernetes with add-ons",
+			groups:               []string{"apps", "cert-manager.io", "monitoring.coreos.com", "gateway.networking.k8s.io"},
+			expectedCapabilities: []string{"CertManager", "GatewayAPI", "PrometheusOperator"},
+		},
+		{
+			name:                 "OpenShift",
+			groups:               []string{"apps", "config.openshift.io", "route.openshift.io", "security.openshift.io", "monitoring.coreos.com"},
+			expectedOpenShift:    true,
+			expectedCapabilities: []string{"ClusterProxy", "PrometheusOperator", "Routes", "SecurityContextConstraints", "ServiceCA"},
+		},
+		{
+			name:                 "OpenShift forced by the environment",
+			groups:               []string{"apps", "cert-manager.io"},
+			env:                  "openshift",
+			expectedOpenShift:    true,
+			expectedCapabilities: []string{"CertManager", "Routes", "SecurityContextConstraints", "ServiceCA"},
+		},
+		{
+			name:                 "Kubernetes forced by the environment",
+			groups:               []string{"apps", "config.openshift.io", "route.openshift.io", "security.openshift.io", "cert-manager.io"},
+			env:                  "kubernetes",
+			expectedCapabilities: []string{"CertManager"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := o.NewWithT(t)
+			t.Setenv(PlatformEnv, tc.env)
+			p, err := Detect(discoveryClient(tc.groups...))
+			g.Expect(err).NotTo(o.HaveOccurred())
+			g.Expect(p.OpenShift).To(o.Equal(tc.expectedOpenShift))
+			g.Expect(p.Capabilities.List()).To(o.Equal(tc.expectedCapabilities))
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	g := o.NewWithT(t)
+	t.Setenv(PlatformEnv, "")
+	g.Expect(FromEnv().OpenShift).To(o.BeFalse())
+	t.Setenv(PlatformEnv, "openshift")
+	p := FromEnv()
+	g.Expect(p.OpenShift).To(o.BeTrue())
+	g.Expect(p.Name()).To(o.Equal("OpenShift"))
+	g.Expect(p.Capabilities.Has(ServiceCA)).To(o.BeTrue())
+	g.Expect(p.Capabilities.Has(CertManager)).To(o.BeFalse())
+}
+
+func TestNew(t *testing.T) {
+	g := o.NewWithT(t)
+
+	p, err := New("OpenShift", []string{"certmanager"})
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(p.OpenShift).To(o.BeTrue())
+	g.Expect(p.Capabilities.List()).To(o.Equal([]string{"CertManager", "Routes", "SecurityContextConstraints", "ServiceCA"}))
+
+	p, err = New("", nil)
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(p.Name()).To(o.Equal("Kubernetes"))
+	g.Expect(p.Capabilities.List()).To(o.BeEmpty())
+
+	_, err = New("nomad", nil)
+	g.Expect(err).To(o.MatchError(o.ContainSubstring(`unknown platform "nomad"`)))
+	_, err = New("kubernetes", []string{"Routes", "Mesh"})
+	g.Expect(err).To(o.MatchError(o.ContainSubstring(`unknown capability "Mesh"`)))
+}
@@ -0,0 +1,43 @@
+package platform
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+// ClusterProxyName is the name of the OpenShift cluster-wide Proxy.
+const ClusterProxyName = "cluster"
+
+// ProxyGroupVersionKind is the kind of the OpenShift cluster-wide Proxy.
+var ProxyGroupVersionKind = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Proxy"}
+
+// GetClusterProxy returns the effective configuration of the OpenShift cluster-wide Proxy, taken
+// from its status, or nil when it does not exist.
+func GetClusterProxy(ctx context.Context, c client.Reader) (*v1alpha1.ProxySpec, error) {
+	proxy := &unstructured.Unstructured{}
+	proxy.SetGroupVersionKind(ProxyGroupVersionKind)
+	err := c.Get(ctx, types.NamespacedName{Name: ClusterProxyName}, proxy)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status, _, err := unstructured.NestedStringMap(proxy.Object, "status")
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.ProxySpec{
+		HTTPProxy:  status["httpProxy"],
+		HTTPSProxy: status["httpsProxy"],
+		NoProxy:    status["noProxy"],
+	}, nil
+}
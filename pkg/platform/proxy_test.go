@@ -0,0 +1,37 @@
+package platform
+
+import (
+	"context"
+	"testing"
+
+	o "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/shipwright-io/operator/api/v1alpha1"
+)
+
+func TestGetClusterProxy(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx := context.TODO()
+
+	proxy, err := GetClusterProxy(ctx, fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(proxy).To(o.BeNil())
+
+	clusterProxy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cluster"},
+		"spec":     map[string]interface{}{"httpProxy": "http://proxy:3128", "readinessEndpoints": []interface{}{"http://example.com"}},
+		"status": map[string]interface{}{
+			"httpProxy": "http://proxy:3128",
+			"noProxy":   ".cluster.local,.svc,10.0.0.0/16,localhost",
+		},
+	}}
+	clusterProxy.SetGroupVersionKind(ProxyGroupVersionKind)
+	proxy, err = GetClusterProxy(ctx, fake.NewClientBuilder().WithObjects(clusterProxy).Build())
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(proxy).To(o.Equal(&v1alpha1.ProxySpec{
+		HTTPProxy: "http://proxy:3128",
+		NoProxy:   ".cluster.local,.svc,10.0.0.0/16,localhost",
+	}))
+}
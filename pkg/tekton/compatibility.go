@@ -0,0 +1,148 @@
+package tekton
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PipelinesInfoConfigMap is the ConfigMap holding the installed Tekton Pipelines version.
+	PipelinesInfoConfigMap = "pipelines-info"
+	// FeatureFlagsConfigMap is the ConfigMap holding the Tekton Pipelines feature flags.
+	FeatureFlagsConfigMap = "feature-flags"
+)
+
+// SupportMatrix lists the Tekton Pipelines versions and feature flags supported by the Shipwright
+// Build releases.
+type SupportMatrix []BuildSupport
+
+// BuildSupport describes the Tekton Pipelines versions and feature flags supported by a Shipwright
+// Build minor release.
+type BuildSupport struct {
+	// Build is the Shipwright Build minor version, e.g. "v0.20".
+	Build string `json:"build"`
+	// Pipelines is the supported Tekton Pipelines version range.
+	Pipelines VersionRange `json:"pipelines"`
+	// FeatureFlags lists the supported values of Tekton Pipelines feature flags.
+	FeatureFlags map[string][]string `json:"featureFlags,omitempty"`
+}
+
+// VersionRange is a semantic version range, which includes Min and excludes Max. Either bound can
+// be omitted.
+type VersionRange struct {
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+}
+
+// LoadSupportMatrix reads the support matrix from the given file.
+func LoadSupportMatrix(path string) (SupportMatrix, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	matrix := SupportMatrix{}
+	if err := yaml.Unmarshal(data, &matrix); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return matrix, nil
+}
+
+// Check compares the installed Tekton Pipelines version and feature flags against the support
+// matrix entry of the given Shipwright Build version. It returns the status, reason and message of
+// the compatibility, with the exact mismatch when they are not compatible. The status is unknown
+// when the versions cannot be compared.
+func (m SupportMatrix) Check(buildVersion, pipelinesVersion string, featureFlags map[string]string) (metav1.ConditionStatus, string, string) {
+	build, err := version.ParseSemantic(buildVersion)
+	if err != nil {
+		return metav1.ConditionUnknown, "UnknownBuildVersion",
+			fmt.Sprintf("Shipwright Build version %q is not a semantic version", buildVersion)
+	}
+	support := m.supportFor(build)
+	if support == nil {
+		return metav1.ConditionUnknown, "UnknownBuildVersion",
+			fmt.Sprintf("No Tekton Pipelines support information for Shipwright Build %s", buildVersion)
+	}
+	if pipelinesVersion == "" {
+		return metav1.ConditionUnknown, "UnknownPipelinesVersion",
+			"The Tekton Pipelines version is not found in the pipelines-info ConfigMap"
+	}
+	pipelines, err := version.ParseSemantic(pipelinesVersion)
+	if err != nil {
+		return metav1.ConditionUnknown, "UnknownPipelinesVersion",
+			fmt.Sprintf("Tekton Pipelines version %q is not a semantic version", pipelinesVersion)
+	}
+
+	if !support.Pipelines.contains(pipelines) {
+		return metav1.ConditionFalse, "UnsupportedPipelinesVersion",
+			fmt.Sprintf("Tekton Pipelines %s is not supported by Shipwright Build %s, which requires %s",
+				pipelinesVersion, buildVersion, support.Pipelines)
+	}
+
+	mismatches := []string{}
+	for _, flag := range slices.Sorted(maps.Keys(support.FeatureFlags)) {
+		value, set := featureFlags[flag]
+		if !set {
+			continue
+		}
+		if allowed := support.FeatureFlags[flag]; !slices.Contains(allowed, value) {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %q, expected one of %q", flag, value, allowed))
+		}
+	}
+	if len(mismatches) > 0 {
+		return metav1.ConditionFalse, "UnsupportedFeatureFlags",
+			fmt.Sprintf("Tekton Pipelines feature flags are not supported by Shipwright Build %s: %s",
+				buildVersion, strings.Join(mismatches, "; "))
+	}
+	return metav1.ConditionTrue, "Compatible",
+		fmt.Sprintf("Tekton Pipelines %s is supported by Shipwright Build %s", pipelinesVersion, buildVersion)
+}
+
+// supportFor returns the entry matching the major and minor version of the given Build version.
+func (m SupportMatrix) supportFor(build *version.Version) *BuildSupport {
+	for i := range m {
+		v, err := version.ParseGeneric(m[i].Build)
+		if err != nil {
+			continue
+		}
+		if v.Major() == build.Major() && v.Minor() == build.Minor() {
+			return &m[i]
+		}
+	}
+	return nil
+}
+
+func (r VersionRange) contains(v *version.Version) bool {
+	if r.Min != "" {
+		if lower, err := version.ParseSemantic(r.Min); err == nil && v.LessThan(lower) {
+			return false
+		}
+	}
+	if r.Max != "" {
+		if upper, err := version.ParseSemantic(r.Max); err == nil && !v.LessThan(upper) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the range, e.g. ">= v0.65.0, < v2.0.0".
+func (r VersionRange) String() string {
+	bounds := []string{}
+	if r.Min != "" {
+		bounds = append(bounds, ">= "+r.Min)
+	}
+	if r.Max != "" {
+		bounds = append(bounds, "< "+r.Max)
+	}
+	if len(bounds) == 0 {
+		return "any version"
+	}
+	return strings.Join(bounds, ", ")
+}
@@ -0,0 +1,109 @@
+package tekton
+
+import (
+	"path/filepath"
+	"testing"
+
+	o "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/shipwright-io/operator/pkg/common"
+)
+
+func TestSupportMatrixCheck(t *testing.T) {
+	matrix := SupportMatrix{{
+		Build:     "v0.20",
+		Pipelines: VersionRange{Min: "v0.65.0", Max: "v2.0.0"},
+		FeatureFlags: map[string][]string{
+			"enforce-nonfalsifiability": {"none"},
+		},
+	}}
+
+	cases := []struct {
+		name             string
+		buildVersion     string
+		pipelinesVersion string
+		featureFlags     map[string]string
+		expectStatus     metav1.ConditionStatus
+		expectReason     string
+		expectMessage    string
+	}{
+		{
+			name:             "supported",
+			buildVersion:     "v0.20.1",
+			pipelinesVersion: "v1.12.0",
+			featureFlags:     map[string]string{"enforce-nonfalsifiability": "none", "enable-api-fields": "beta"},
+			expectStatus:     metav1.ConditionTrue,
+			expectReason:     "Compatible",
+			expectMessage:    "Tekton Pipelines v1.12.0 is supported by Shipwright Build v0.20.1",
+		},
+		{
+			name:             "Build version is not semantic",
+			buildVersion:     "nightly",
+			pipelinesVersion: "v1.12.0",
+			expectStatus:     metav1.ConditionUnknown,
+			expectReason:     "UnknownBuildVersion",
+		},
+		{
+			name:             "Build version is not in the matrix",
+			buildVersion:     "v0.21.0",
+			pipelinesVersion: "v1.12.0",
+			expectStatus:     metav1.ConditionUnknown,
+			expectReason:     "UnknownBuildVersion",
+		},
+		{
+			name:         "Pipelines version is missing",
+			buildVersion: "v0.20.0",
+			expectStatus: metav1.ConditionUnknown,
+			expectReason: "UnknownPipelinesVersion",
+		},
+		{
+			name:             "Pipelines version is too old",
+			buildVersion:     "v0.20.0",
+			pipelinesVersion: "v0.59.2",
+			expectStatus:     metav1.ConditionFalse,
+			expectReason:     "UnsupportedPipelinesVersion",
+			expectMessage:    "Tekton Pipelines v0.59.2 is not supported by Shipwright Build v0.20.0, which requires >= v0.65.0, < v2.0.0",
+		},
+		{
+			name:             "Pipelines version is too recent",
+			buildVersion:     "v0.20.0",
+			pipelinesVersion: "v2.0.0",
+			expectStatus:     metav1.ConditionFalse,
+			expectReason:     "UnsupportedPipelinesVersion",
+		},
+		{
+			name:             "unsupported feature flag",
+			buildVersion:     "v0.20.0",
+			pipelinesVersion: "v1.12.0",
+			featureFlags:     map[string]string{"enforce-nonfalsifiability": "spire"},
+			expectStatus:     metav1.ConditionFalse,
+			expectReason:     "UnsupportedFeatureFlags",
+			expectMessage:    `Tekton Pipelines feature flags are not supported by Shipwright Build v0.20.0: enforce-nonfalsifiability is "spire", expected one of ["none"]`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := o.NewWithT(t)
+			status, reason, message := matrix.Check(tc.buildVersion, tc.pipelinesVersion, tc.featureFlags)
+			g.Expect(status).To(o.Equal(tc.expectStatus))
+			g.Expect(reason).To(o.Equal(tc.expectReason))
+			if tc.expectMessage != "" {
+				g.Expect(message).To(o.Equal(tc.expectMessage))
+			}
+		})
+	}
+}
+
+func TestLoadSupportMatrix(t *testing.T) {
+	g := o.NewWithT(t)
+	dataPath, err := common.KoDataPath()
+	g.Expect(err).NotTo(o.HaveOccurred())
+	matrix, err := LoadSupportMatrix(filepath.Join(dataPath, "tekton-compatibility.yaml"))
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Expect(matrix).NotTo(o.BeEmpty())
+	for _, support := range matrix {
+		g.Expect(support.Build).NotTo(o.BeEmpty())
+		g.Expect(support.Pipelines.Min).NotTo(o.BeEmpty())
+	}
+}
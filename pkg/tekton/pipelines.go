@@ -0,0 +1,39 @@
+package tekton
+
+import (
+	"context"
+
+	"github.com/manifestival/manifestival"
+	crdclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PipelinesNamespace is the namespace where the Tekton Pipelines release manifest deploys its
+	// components.
+	PipelinesNamespace = "tekton-pipelines"
+	// PipelinesControllerDeployment is the name of the Tekton Pipelines controller Deployment.
+	PipelinesControllerDeployment = "tekton-pipelines-controller"
+	// PipelinesWebhookDeployment is the name of the Tekton Pipelines webhook Deployment.
+	PipelinesWebhookDeployment = "tekton-pipelines-webhook"
+
+	taskRunsCRD = "taskruns.tekton.dev"
+)
+
+// ReconcileTektonPipelines installs or upgrades Tekton Pipelines from the given release manifest.
+// Tekton Pipelines installed by other means, for instance by the Tekton Operator, is left
+// untouched: only the installations whose TaskRun CRD carries the given label, which is set on
+// the manifest resources, are upgraded.
+func ReconcileTektonPipelines(ctx context.Context, crdClient crdclientv1.ApiextensionsV1Interface, manifest manifestival.Manifest, label string) error {
+	crd, err := crdClient.CustomResourceDefinitions().Get(ctx, taskRunsCRD, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if _, managed := crd.Labels[label]; !managed {
+			return nil
+		}
+	}
+	return manifest.Apply()
+}
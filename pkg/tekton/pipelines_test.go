@@ -0,0 +1,85 @@
+package tekton
+
+import (
+	"context"
+	"testing"
+
+	mfc "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	o "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileTektonPipelines(t *testing.T) {
+	const label = "operator.shipwright.io/shipwrightbuild"
+
+	cases := []struct {
+		name          string
+		taskRunCRD    *apiextensionsv1.CustomResourceDefinition
+		expectApplied bool
+	}{
+		{
+			name:          "Tekton Pipelines not installed",
+			expectApplied: true,
+		},
+		{
+			name: "Tekton Pipelines installed by the operator",
+			taskRunCRD: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "taskruns.tekton.dev",
+					Labels: map[string]string{label: "name"},
+				},
+			},
+			expectApplied: true,
+		},
+		{
+			name: "Tekton Pipelines installed by other means",
+			taskRunCRD: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "taskruns.tekton.dev",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := o.NewWithT(t)
+			ctx := context.TODO()
+			crds := []runtime.Object{}
+			if tc.taskRunCRD != nil {
+				crds = append(crds, tc.taskRunCRD)
+			}
+			crdClient := apiextensionsfake.NewSimpleClientset(crds...)
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+			configMap := unstructured.Unstructured{}
+			configMap.SetAPIVersion("v1")
+			configMap.SetKind("ConfigMap")
+			configMap.SetNamespace(PipelinesNamespace)
+			configMap.SetName("feature-flags")
+			configMap.SetLabels(map[string]string{label: "name"})
+			manifest, err := manifestival.ManifestFrom(manifestival.Slice([]unstructured.Unstructured{configMap}),
+				manifestival.UseClient(mfc.NewClient(k8sClient)))
+			g.Expect(err).NotTo(o.HaveOccurred())
+
+			err = ReconcileTektonPipelines(ctx, crdClient.ApiextensionsV1(), manifest, label)
+			g.Expect(err).NotTo(o.HaveOccurred())
+
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: PipelinesNamespace, Name: "feature-flags"}, &corev1.ConfigMap{})
+			if tc.expectApplied {
+				g.Expect(err).NotTo(o.HaveOccurred())
+			} else {
+				g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+			}
+		})
+	}
+}
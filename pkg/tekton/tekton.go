@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/common"
 	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/apis/operator/v1alpha1"
 	tektonoperatorclientv1alpha1 "github.com/tektoncd/operator/pkg/client/clientset/versioned/typed/operator/v1alpha1"
@@ -12,14 +13,23 @@ import (
 	"k8s.io/apimachinery/pkg/util/version"
 )
 
+const (
+	// defaultPrunerKeep is the number of resources kept by the pruner by default. This matches
+	// the Tekton operator default.
+	defaultPrunerKeep = 100
+)
+
 // ReconcileTekton ensures that Tekton Pipelines has been installed.
 // If Tekton Pipelines has not been installed, ReconcileTekton will create a TektonConfig object
-// with the "lite" profile so that the Tekton Operator deploys Tekton Pipelines.
+// configured by the given spec, with the "lite" profile by default, so that the Tekton Operator
+// deploys Tekton Pipelines. When the spec disables the TektonConfig management, an error is
+// returned instead.
 // If a TektonConfig already exists, ReconcileTekton leaves it untouched, regardless of its
 // profile, so that externally managed configurations are preserved.
 func ReconcileTekton(ctx context.Context,
 	crdClient crdclientv1.ApiextensionsV1Interface,
-	tektonOperatorClient tektonoperatorclientv1alpha1.OperatorV1alpha1Interface) (*tektonoperatorv1alpha1.TektonConfig, bool, error) {
+	tektonOperatorClient tektonoperatorclientv1alpha1.OperatorV1alpha1Interface,
+	spec *v1alpha1.TektonSpec) (*tektonoperatorv1alpha1.TektonConfig, bool, error) {
 	pipelinesInstalled, err := IsTektonPipelinesInstalled(ctx, crdClient)
 	if err != nil {
 		return nil, true, err
@@ -38,7 +48,7 @@ func ReconcileTekton(ctx context.Context,
 	if err != nil {
 		return nil, true, fmt.Errorf("failed to determine Tekton Operator version: %v", err)
 	}
-	if tektonVersion.Major() < common.TektonOpMinSupportedMajor+1 && tektonVersion.Minor() < common.TektonOpMinSupportedMinor {
+	if !tektonVersion.AtLeast(version.MustParseSemantic(common.TektonOpMinSupportedVersion)) {
 		return nil, true, fmt.Errorf("insufficient Tekton Operator version - must be greater than %s", common.TektonOpMinSupportedVersion)
 	}
 
@@ -49,9 +59,13 @@ func ReconcileTekton(ctx context.Context,
 	if tektonConfigPresent {
 		return nil, false, nil
 	}
+	if spec != nil && spec.Manage == v1alpha1.TektonManagementNever {
+		return nil, false, fmt.Errorf("no TektonConfig found, and spec.tekton.manage is set to %q", v1alpha1.TektonManagementNever)
+	}
 
+	profile, targetNamespace, pruner := tektonConfigSettings(spec)
 	tektonConfig, err := CreateTektonConfigWithProfileAndTargetNamespace(ctx,
-		tektonOperatorClient, tektonoperatorv1alpha1.ProfileLite, "tekton-pipelines")
+		tektonOperatorClient, profile, targetNamespace, pruner)
 	if err != nil {
 		return tektonConfig, true, err
 	}
@@ -101,25 +115,46 @@ func IsTektonConfigPresent(ctx context.Context, client tektonoperatorclientv1alp
 	return len(list.Items) > 0, err
 }
 
-// CreateTektonConfigWithProfileAndTargetNamespace creates a TektonConfig object with the given
-// profile and target namespace for Tekton components.
-func CreateTektonConfigWithProfileAndTargetNamespace(ctx context.Context, client tektonoperatorclientv1alpha1.OperatorV1alpha1Interface, profile string, targetNamepsace string) (*tektonoperatorv1alpha1.TektonConfig, error) {
+// tektonConfigSettings returns the profile, target namespace and pruner of the TektonConfig to
+// create, from the given spec and the defaults.
+func tektonConfigSettings(spec *v1alpha1.TektonSpec) (string, string, tektonoperatorv1alpha1.Prune) {
+	profile := tektonoperatorv1alpha1.ProfileLite
+	targetNamespace := PipelinesNamespace
 	// If creating a TektonConfig, enable the pruner with default keep of 100
-	// This matches the Tekton operator default
-	keep := uint(100)
+	keep := uint(defaultPrunerKeep)
+	pruner := tektonoperatorv1alpha1.Prune{Keep: &keep}
+	if spec == nil {
+		return profile, targetNamespace, pruner
+	}
+	if spec.Profile != "" {
+		profile = spec.Profile
+	}
+	if spec.TargetNamespace != "" {
+		targetNamespace = spec.TargetNamespace
+	}
+	if spec.Pruner != nil {
+		pruner.Schedule = spec.Pruner.Schedule
+		pruner.Resources = spec.Pruner.Resources
+		if spec.Pruner.Keep != nil {
+			keep = uint(*spec.Pruner.Keep)
+		}
+	}
+	return profile, targetNamespace, pruner
+}
+
+// CreateTektonConfigWithProfileAndTargetNamespace creates a TektonConfig object with the given
+// profile, target namespace and pruner for Tekton components.
+func CreateTektonConfigWithProfileAndTargetNamespace(ctx context.Context, client tektonoperatorclientv1alpha1.OperatorV1alpha1Interface, profile string, targetNamepsace string, pruner tektonoperatorv1alpha1.Prune) (*tektonoperatorv1alpha1.TektonConfig, error) {
 	tektonConfig := &tektonoperatorv1alpha1.TektonConfig{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "config",
+			Name: TektonConfigName,
 		},
 		Spec: tektonoperatorv1alpha1.TektonConfigSpec{
 			Profile: profile,
 			CommonSpec: tektonoperatorv1alpha1.CommonSpec{
 				TargetNamespace: targetNamepsace,
 			},
-			Pruner: tektonoperatorv1alpha1.Prune{
-				Disabled: false,
-				Keep:     &keep,
-			},
+			Pruner: pruner,
 		},
 	}
 	return client.TektonConfigs().Create(ctx, tektonConfig, metav1.CreateOptions{})
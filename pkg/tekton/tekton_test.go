@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/shipwright-io/operator/api/v1alpha1"
 	"github.com/shipwright-io/operator/pkg/common"
 
 	o "github.com/onsi/gomega"
@@ -18,6 +19,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/version"
 	ktesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
 )
 
 func TestReconcileTekton(t *testing.T) {
@@ -26,10 +28,12 @@ func TestReconcileTekton(t *testing.T) {
 		taskRunCRD                     *apiextensionsv1.CustomResourceDefinition
 		tektonConfigCRD                *apiextensionsv1.CustomResourceDefinition
 		tektonConfigObj                *tektonoperatorv1alpha1.TektonConfig
+		spec                           *v1alpha1.TektonSpec
 		createTektonConfigErr          error
 		expectError                    bool
 		expectRequeue                  bool
 		expectTektonConfigCreateAction bool
+		expectTektonConfigSpec         *tektonoperatorv1alpha1.TektonConfigSpec
 	}{
 		{
 			name:        "No Tekton Objects",
@@ -78,6 +82,50 @@ func TestReconcileTekton(t *testing.T) {
 			},
 			expectTektonConfigCreateAction: true,
 		},
+		{
+			name: "TektonConfig created from spec",
+			tektonConfigCRD: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tektonconfigs.operator.tekton.dev",
+					Labels: map[string]string{
+						"operator.tekton.dev/release": common.TektonOpMinSupportedVersion,
+					},
+				},
+			},
+			spec: &v1alpha1.TektonSpec{
+				Manage:          v1alpha1.TektonManagementCreate,
+				Profile:         tektonoperatorv1alpha1.ProfileBasic,
+				TargetNamespace: "pipelines",
+				Pruner: &v1alpha1.TektonPrunerSpec{
+					Schedule:  "0 8 * * *",
+					Keep:      ptr.To[int32](10),
+					Resources: []string{"pipelinerun"},
+				},
+			},
+			expectTektonConfigCreateAction: true,
+			expectTektonConfigSpec: &tektonoperatorv1alpha1.TektonConfigSpec{
+				Profile:    tektonoperatorv1alpha1.ProfileBasic,
+				CommonSpec: tektonoperatorv1alpha1.CommonSpec{TargetNamespace: "pipelines"},
+				Pruner: tektonoperatorv1alpha1.Prune{
+					Schedule:  "0 8 * * *",
+					Keep:      ptr.To[uint](10),
+					Resources: []string{"pipelinerun"},
+				},
+			},
+		},
+		{
+			name: "TektonConfig management disabled",
+			tektonConfigCRD: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tektonconfigs.operator.tekton.dev",
+					Labels: map[string]string{
+						"operator.tekton.dev/release": common.TektonOpMinSupportedVersion,
+					},
+				},
+			},
+			spec:        &v1alpha1.TektonSpec{Manage: v1alpha1.TektonManagementNever},
+			expectError: true,
+		},
 		{
 			name: "Create TektonConfig error",
 			tektonConfigCRD: &apiextensionsv1.CustomResourceDefinition{
@@ -157,7 +205,7 @@ func TestReconcileTekton(t *testing.T) {
 					return true, nil, tc.createTektonConfigErr
 				})
 			}
-			tektonConfig, requeue, err := ReconcileTekton(ctx, crdClient.ApiextensionsV1(), tektonOperatorClient.OperatorV1alpha1())
+			tektonConfig, requeue, err := ReconcileTekton(ctx, crdClient.ApiextensionsV1(), tektonOperatorClient.OperatorV1alpha1(), tc.spec)
 			if tc.expectError {
 				g.Expect(err).To(o.HaveOccurred())
 			} else {
@@ -177,10 +225,17 @@ func TestReconcileTekton(t *testing.T) {
 			if tc.expectTektonConfigCreateAction && tc.createTektonConfigErr == nil {
 				g.Expect(tektonConfig).NotTo(o.BeNil())
 				g.Expect(tektonConfig.Name).To(o.Equal("config"))
-				g.Expect(tektonConfig.Spec.Profile).To(o.Equal(tektonoperatorv1alpha1.ProfileLite))
-				g.Expect(tektonConfig.Spec.TargetNamespace).To(o.Equal("tekton-pipelines"))
-				g.Expect(tektonConfig.Spec.Pruner.Disabled).To(o.Equal(false))
-				g.Expect(tektonConfig.Spec.Pruner.Keep).NotTo(o.BeNil())
+				expectedSpec := tc.expectTektonConfigSpec
+				if expectedSpec == nil {
+					expectedSpec = &tektonoperatorv1alpha1.TektonConfigSpec{
+						Profile:    tektonoperatorv1alpha1.ProfileLite,
+						CommonSpec: tektonoperatorv1alpha1.CommonSpec{TargetNamespace: "tekton-pipelines"},
+						Pruner:     tektonoperatorv1alpha1.Prune{Keep: ptr.To[uint](100)},
+					}
+				}
+				g.Expect(tektonConfig.Spec.Profile).To(o.Equal(expectedSpec.Profile))
+				g.Expect(tektonConfig.Spec.TargetNamespace).To(o.Equal(expectedSpec.TargetNamespace))
+				g.Expect(tektonConfig.Spec.Pruner).To(o.Equal(expectedSpec.Pruner))
 			}
 		})
 	}
@@ -449,7 +504,8 @@ func TestCreateTektonConfig(t *testing.T) {
 	tektonConfig, err := CreateTektonConfigWithProfileAndTargetNamespace(ctx,
 		client.OperatorV1alpha1(),
 		expectedProfile,
-		expectedNamespace)
+		expectedNamespace,
+		tektonoperatorv1alpha1.Prune{})
 	if len(client.Actions()) != 1 {
 		t.Errorf("expected 1 client action, got %d", len(client.Actions()))
 	}
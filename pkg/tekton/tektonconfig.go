@@ -0,0 +1,102 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/apis/operator/v1alpha1"
+	tektonoperatorclientv1alpha1 "github.com/tektoncd/operator/pkg/client/clientset/versioned/typed/operator/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+)
+
+// TektonConfigName is the name of the TektonConfig created by the operator.
+const TektonConfigName = "config"
+
+// GetTektonConfig returns the TektonConfig of the cluster. The TektonConfig named "config" is
+// preferred, otherwise the first one by name is returned. A not found error is returned when there
+// is no TektonConfig.
+func GetTektonConfig(ctx context.Context, client tektonoperatorclientv1alpha1.OperatorV1alpha1Interface) (*tektonoperatorv1alpha1.TektonConfig, error) {
+	list, err := client.TektonConfigs().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if list == nil || len(list.Items) == 0 {
+		return nil, errors.NewNotFound(schema.GroupResource{
+			Group:    tektonoperatorv1alpha1.SchemeGroupVersion.Group,
+			Resource: "tektonconfigs",
+		}, TektonConfigName)
+	}
+	items := slices.Clone(list.Items)
+	slices.SortFunc(items, func(a, b tektonoperatorv1alpha1.TektonConfig) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i := range items {
+		if items[i].Name == TektonConfigName {
+			return &items[i], nil
+		}
+	}
+	return &items[0], nil
+}
+
+// IsTektonConfigReady checks if the "Ready" condition of the TektonConfig reports true.
+func IsTektonConfigReady(tektonConfig *tektonoperatorv1alpha1.TektonConfig) bool {
+	condition := tektonConfig.Status.GetCondition(apis.ConditionReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// TektonConfigNotReadySince returns the time of the last transition of the "Ready" condition of the
+// TektonConfig, or the zero time when it is unknown.
+func TektonConfigNotReadySince(tektonConfig *tektonoperatorv1alpha1.TektonConfig) time.Time {
+	condition := tektonConfig.Status.GetCondition(apis.ConditionReady)
+	if condition == nil {
+		return time.Time{}
+	}
+	return condition.LastTransitionTime.Inner.Time
+}
+
+// NotReadyComponents describes the TektonConfig conditions, other than "Ready", which do not report
+// true, e.g. `ComponentsReady: TektonPipeline: reconcile again and proceed`.
+func NotReadyComponents(tektonConfig *tektonoperatorv1alpha1.TektonConfig) []string {
+	components := []string{}
+	for _, condition := range tektonConfig.Status.Conditions {
+		if condition.Type == apis.ConditionReady || condition.Status == corev1.ConditionTrue {
+			continue
+		}
+		component := string(condition.Type)
+		if condition.Message != "" {
+			component = fmt.Sprintf("%s: %s", component, condition.Message)
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// TektonConfigReadinessChanged checks whether the readiness of the TektonConfig, or the conditions
+// which are not ready, differ between the two given objects.
+func TektonConfigReadinessChanged(oldObj, newObj *tektonoperatorv1alpha1.TektonConfig) bool {
+	return IsTektonConfigReady(oldObj) != IsTektonConfigReady(newObj) ||
+		!slices.Equal(NotReadyComponents(oldObj), NotReadyComponents(newObj))
+}
+
+// NewTektonConfigInformer returns an informer of the TektonConfig objects, backed by the Tekton
+// Operator client. The informer must be started with Run.
+func NewTektonConfigInformer(client tektonoperatorclientv1alpha1.OperatorV1alpha1Interface, resync time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			return client.TektonConfigs().List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			return client.TektonConfigs().Watch(ctx, options)
+		},
+	}, client), &tektonoperatorv1alpha1.TektonConfig{}, resync, cache.Indexers{})
+}
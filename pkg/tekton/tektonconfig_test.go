@@ -0,0 +1,135 @@
+package tekton
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	o "github.com/onsi/gomega"
+
+	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/apis/operator/v1alpha1"
+	tektonoperatorfake "github.com/tektoncd/operator/pkg/client/clientset/versioned/fake"
+	tektonoperatorclientv1alpha1 "github.com/tektoncd/operator/pkg/client/clientset/versioned/typed/operator/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func tektonConfig(name string, conditions ...apis.Condition) *tektonoperatorv1alpha1.TektonConfig {
+	return &tektonoperatorv1alpha1.TektonConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: tektonoperatorv1alpha1.TektonConfigStatus{
+			Status: duckv1.Status{Conditions: conditions},
+		},
+	}
+}
+
+func TestGetTektonConfig(t *testing.T) {
+	cases := []struct {
+		name          string
+		tektonConfigs []runtime.Object
+		expectName    string
+		expectMissing bool
+	}{
+		{
+			name:          "No TektonConfig",
+			expectMissing: true,
+		},
+		{
+			name:          "TektonConfig named config",
+			tektonConfigs: []runtime.Object{tektonConfig("all"), tektonConfig("config")},
+			expectName:    "config",
+		},
+		{
+			name:          "TektonConfig with another name",
+			tektonConfigs: []runtime.Object{tektonConfig("tekton"), tektonConfig("default")},
+			expectName:    "default",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := o.NewWithT(t)
+			client := tektonoperatorfake.NewSimpleClientset(tc.tektonConfigs...)
+			tektonConfig, err := GetTektonConfig(context.TODO(), client.OperatorV1alpha1())
+			if tc.expectMissing {
+				g.Expect(errors.IsNotFound(err)).To(o.BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(o.HaveOccurred())
+			g.Expect(tektonConfig.Name).To(o.Equal(tc.expectName))
+		})
+	}
+}
+
+func TestTektonConfigReadiness(t *testing.T) {
+	g := o.NewWithT(t)
+	since := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	notReady := tektonConfig("config",
+		apis.Condition{
+			Type:               apis.ConditionReady,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(since)},
+		},
+		apis.Condition{Type: tektonoperatorv1alpha1.PreInstall, Status: corev1.ConditionTrue},
+		apis.Condition{
+			Type:    tektonoperatorv1alpha1.ComponentsReady,
+			Status:  corev1.ConditionFalse,
+			Message: "TektonPipeline: reconcile again and proceed",
+		},
+		apis.Condition{Type: tektonoperatorv1alpha1.PostInstall, Status: corev1.ConditionUnknown},
+	)
+	g.Expect(IsTektonConfigReady(notReady)).To(o.BeFalse())
+	g.Expect(TektonConfigNotReadySince(notReady)).To(o.BeTemporally("==", since))
+	g.Expect(NotReadyComponents(notReady)).To(o.Equal([]string{
+		"ComponentsReady: TektonPipeline: reconcile again and proceed",
+		"PostInstall",
+	}))
+
+	ready := tektonConfig("config", apis.Condition{Type: apis.ConditionReady, Status: corev1.ConditionTrue})
+	g.Expect(IsTektonConfigReady(ready)).To(o.BeTrue())
+	g.Expect(NotReadyComponents(ready)).To(o.BeEmpty())
+	g.Expect(TektonConfigReadinessChanged(notReady, ready)).To(o.BeTrue())
+	g.Expect(TektonConfigReadinessChanged(ready, ready.DeepCopy())).To(o.BeFalse())
+
+	missing := tektonConfig("config")
+	g.Expect(IsTektonConfigReady(missing)).To(o.BeFalse())
+	g.Expect(TektonConfigNotReadySince(missing).IsZero()).To(o.BeTrue())
+}
+
+// fakeOperatorClient is a fake Tekton Operator client, which does not support the watch-list
+// semantics, like the Kubernetes fake clientsets.
+type fakeOperatorClient struct {
+	tektonoperatorclientv1alpha1.OperatorV1alpha1Interface
+}
+
+func (fakeOperatorClient) IsWatchListSemanticsUnSupported() bool {
+	return true
+}
+
+func TestNewTektonConfigInformer(t *testing.T) {
+	g := o.NewWithT(t)
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	client := tektonoperatorfake.NewSimpleClientset()
+	informer := NewTektonConfigInformer(fakeOperatorClient{client.OperatorV1alpha1()}, 0)
+	added := make(chan string, 1)
+	_, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			added <- obj.(*tektonoperatorv1alpha1.TektonConfig).Name
+		},
+	})
+	g.Expect(err).NotTo(o.HaveOccurred())
+	go informer.Run(ctx.Done())
+	g.Expect(toolscache.WaitForCacheSync(ctx.Done(), informer.HasSynced)).To(o.BeTrue())
+
+	_, err = client.OperatorV1alpha1().TektonConfigs().Create(ctx, tektonConfig("config"), metav1.CreateOptions{})
+	g.Expect(err).NotTo(o.HaveOccurred())
+	g.Eventually(added).Should(o.Receive(o.Equal("config")))
+}
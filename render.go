@@ -0,0 +1,124 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	operatorv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/shipwright-io/operator/controllers"
+	"github.com/shipwright-io/operator/pkg/platform"
+)
+
+// renderCommand is the subcommand printing the rendered manifests of a ShipwrightBuild.
+const renderCommand = "render"
+
+// render prints the resources the operator applies for the ShipwrightBuild of the given file,
+// without a cluster connection. The output is stable, so that it can be kept as a golden file: in
+// the BuiltIn certificates mode, the webhook certificates are read from the Secret of the file,
+// and placeholders are printed when it is missing, instead of generating new ones on every run.
+// It returns the exit code of the command.
+func render(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(renderCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "",
+		"File holding the ShipwrightBuild, along with the objects it refers to, such as the trusted CA ConfigMap, the build strategy sources or the BuiltIn webhook certificates Secret, rendered as placeholders when missing. Reads the standard input when set to \"-\".")
+	platformName := flags.String("platform", "kubernetes",
+		"Platform the manifests are rendered for, either \"kubernetes\" or \"openshift\".")
+	capabilities := flags.String("capabilities", "",
+		"Comma-separated capabilities of the platform, e.g. \"CertManager,ClusterProxy\".")
+	koDataPath := flags.String("kodata-path", os.Getenv("KO_DATA_PATH"),
+		"Directory of the embedded release manifests.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(stderr, "render: the ShipwrightBuild file must be set with -f")
+		flags.Usage()
+		return 2
+	}
+	if err := os.Setenv("KO_DATA_PATH", *koDataPath); err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		return 1
+	}
+
+	var names []string
+	if *capabilities != "" {
+		names = strings.Split(*capabilities, ",")
+	}
+	p, err := platform.New(*platformName, names)
+	if err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		return 2
+	}
+	b, objects, err := readShipwrightBuild(*file)
+	if err != nil {
+		fmt.Fprintf(stderr, "render: reading %s: %v\n", *file, err)
+		return 1
+	}
+
+	resources, skipped, err := controllers.Render(context.Background(), b, p, scheme, objects, logr.Discard())
+	if err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		return 1
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(stderr, "render: skipped %v\n", err)
+	}
+	for _, u := range resources {
+		data, err := yaml.Marshal(u.Object)
+		if err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "---\n%s", data)
+	}
+	return 0
+}
+
+// readShipwrightBuild reads the ShipwrightBuild of the file, and the other objects it holds.
+func readShipwrightBuild(file string) (*operatorv1alpha1.ShipwrightBuild, []client.Object, error) {
+	var source manifestival.Source
+	if file == "-" {
+		source = manifestival.Reader(os.Stdin)
+	} else {
+		source = manifestival.Path(file)
+	}
+	manifest, err := manifestival.ManifestFrom(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var b *operatorv1alpha1.ShipwrightBuild
+	objects := []client.Object{}
+	for _, u := range manifest.Resources() {
+		if u.GroupVersionKind() != operatorv1alpha1.GroupVersion.WithKind("ShipwrightBuild") {
+			objects = append(objects, u.DeepCopy())
+			continue
+		}
+		if b != nil {
+			return nil, nil, fmt.Errorf("more than one ShipwrightBuild found")
+		}
+		b = &operatorv1alpha1.ShipwrightBuild{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, b); err != nil {
+			return nil, nil, err
+		}
+	}
+	if b == nil {
+		return nil, nil, fmt.Errorf("no ShipwrightBuild found")
+	}
+	return b, objects, nil
+}